@@ -0,0 +1,37 @@
+package jungle
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+type (
+	admissionHolder struct {
+		admit func() bool
+	}
+)
+
+var (
+	// ErrNotAdmitted is the cause of branches rejected by the admission
+	// controller
+	ErrNotAdmitted = errors.New("jungle: branch not admitted")
+
+	admission atomic.Value
+)
+
+// SetAdmissionController makes all trees call admit before creating a new
+// branch, if it returns false the branch is returned already done, with
+// ErrNotAdmitted as its Cause and its process function is never called.
+//
+// It can be used to apply backpressure, eg.: refusing new branches while
+// memory usage is above a threshold. admit is called on every branch, so it
+// must be cheap and safe for concurrent use. A nil value admits everything.
+func SetAdmissionController(admit func() bool) {
+	admission.Store(admissionHolder{admit})
+}
+
+// admitted returns true if the admission controller allows a new branch
+func admitted() bool {
+	h, _ := admission.Load().(admissionHolder)
+	return h.admit == nil || h.admit()
+}
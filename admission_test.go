@@ -0,0 +1,36 @@
+package jungle
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSetAdmissionController(t *testing.T) {
+	var admit atomic.Bool
+	SetAdmissionController(admit.Load)
+	defer SetAdmissionController(nil)
+
+	parent := New()
+	defer parent.Prune()
+	var runs int32
+	fn := func(Tree) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}
+	rejected := parent.BranchFunc(fn)
+	<-rejected.Done()
+	if !errors.Is(rejected.Cause(), ErrNotAdmitted) {
+		t.Fatalf("expecting ErrNotAdmitted got %v", rejected.Cause())
+	}
+
+	admit.Store(true)
+	accepted := parent.BranchFunc(fn)
+	<-accepted.Done()
+	if accepted.Cause() != nil {
+		t.Fatalf("branch should be admitted, got %v", accepted.Cause())
+	}
+	if atomic.LoadInt32(&runs) != 1 {
+		t.Fatalf("only the admitted branch should run, got %v", atomic.LoadInt32(&runs))
+	}
+}
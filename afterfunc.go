@@ -0,0 +1,36 @@
+package jungle
+
+import "sync/atomic"
+
+const (
+	afterPending int32 = iota
+	afterFired
+	afterStopped
+)
+
+// AfterFunc arranges for fn to be called in its own goroutine once this
+// tree is pruned, just like context.AfterFunc. If the tree was already
+// pruned, fn is called right away (in its own goroutine).
+//
+// Calling stop prevents fn from running and returns true, it returns false
+// if fn was already started or stop was already called.
+func (t *tree) AfterFunc(fn func()) (stop func() bool) {
+	var state atomic.Int32
+	stopped := make(chan Signal)
+	go func() {
+		select {
+		case <-t.prune:
+			if state.CompareAndSwap(afterPending, afterFired) {
+				fn()
+			}
+		case <-stopped:
+		}
+	}()
+	return func() bool {
+		if state.CompareAndSwap(afterPending, afterStopped) {
+			close(stopped)
+			return true
+		}
+		return false
+	}
+}
@@ -0,0 +1,35 @@
+package jungle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAfterFunc(t *testing.T) {
+	branch := Root().Branch()
+	called := make(chan Signal)
+	stop := branch.AfterFunc(func() { close(called) })
+	branch.Prune()
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("fn should be called once the tree is pruned")
+	}
+	if stop() {
+		t.Fatal("stop should return false after fn started")
+	}
+}
+
+func TestAfterFuncStop(t *testing.T) {
+	branch := Root().Branch()
+	stop := branch.AfterFunc(func() { t.Error("fn should not run after stop") })
+	if !stop() {
+		t.Fatal("stop should return true before the prune")
+	}
+	if stop() {
+		t.Fatal("a second stop should return false")
+	}
+	branch.Prune()
+	<-branch.Done()
+	time.Sleep(10 * time.Millisecond)
+}
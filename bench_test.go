@@ -0,0 +1,52 @@
+package jungle
+
+import "testing"
+
+// Allocations per branch, measured with -benchtime 20000x:
+//
+//	                  before          after
+//	BenchmarkBranch       12 allocs/op    10 allocs/op
+//	BenchmarkBranchFunc   15 allocs/op    14 allocs/op
+//	BenchmarkPrune        12 allocs/op    10 allocs/op
+//
+// The channel used to wait for the process function is only created for
+// trees with a process, and the events log is allocated once with room for
+// both events.
+//
+// Prune used to be a request sent to the lifecycle over its own channel,
+// now it closes the prune channel directly (guarded by a sync.Once):
+//
+//	                  before         after
+//	BenchmarkBranch       10 allocs/op    9 allocs/op
+//	BenchmarkBranchFunc   14 allocs/op   13 allocs/op
+//	BenchmarkPrune        10 allocs/op    9 allocs/op
+
+func BenchmarkBranch(b *testing.B) {
+	parent := New()
+	defer parent.Prune()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parent.Branch().Prune()
+	}
+}
+
+func BenchmarkBranchFunc(b *testing.B) {
+	parent := New()
+	defer parent.Prune()
+	fn := func(Tree) error { return nil }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		<-parent.BranchFunc(fn).Done()
+	}
+}
+
+func BenchmarkPrune(b *testing.B) {
+	parent := New()
+	defer parent.Prune()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		branch := parent.Branch()
+		branch.Prune()
+		<-branch.Done()
+	}
+}
@@ -0,0 +1,113 @@
+package jungle
+
+import "time"
+
+type (
+	// BreakerPolicy configures the circuit breaker of a supervised branch.
+	//
+	// After Failures consecutive failures the breaker opens and no restart
+	// happens during Cooldown. Then it becomes half-open and allows a single
+	// trial run. If the trial fails the breaker opens again, if it keeps
+	// running for at least Cooldown the breaker closes.
+	//
+	// A run which lasts at least Cooldown before failing is considered
+	// healthy and resets the failure count.
+	BreakerPolicy struct {
+		Failures int
+		Cooldown time.Duration
+	}
+
+	// BreakerState is the state of a circuit breaker
+	BreakerState int
+)
+
+const (
+	// BreakerClosed means restarts happen as usual
+	BreakerClosed BreakerState = iota
+	// BreakerOpen means restarts are suspended until the cooldown ends
+	BreakerOpen
+	// BreakerHalfOpen means a trial run is in progress
+	BreakerHalfOpen
+)
+
+func (b BreakerState) String() string {
+	switch b {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerState returns the state of the circuit breaker of a supervised
+// branch, branches without a breaker are always closed.
+func (t *tree) BreakerState() BreakerState {
+	if t.supervisor == nil {
+		return BreakerClosed
+	}
+	s := t.supervisor
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkTrial()
+	return s.state
+}
+
+// started records the start of a new run
+func (s *supervisor) started() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runStart = time.Now()
+}
+
+// failed records a failure and returns how long to wait until
+// the next restart
+func (s *supervisor) failed() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.policy.Breaker
+	if b == nil {
+		return s.policy.Backoff
+	}
+	if time.Since(s.runStart) >= b.Cooldown {
+		// healthy run
+		s.state = BreakerClosed
+		s.failures = 0
+	}
+	if s.state == BreakerHalfOpen {
+		s.state = BreakerOpen
+		return b.Cooldown
+	}
+	s.failures++
+	if s.failures >= b.Failures {
+		s.state = BreakerOpen
+		s.failures = 0
+		return b.Cooldown
+	}
+	return s.policy.Backoff
+}
+
+// retry moves an open breaker to half-open, right before the trial run
+func (s *supervisor) retry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == BreakerOpen {
+		s.state = BreakerHalfOpen
+	}
+}
+
+// checkTrial closes a half-open breaker once the trial has been running
+// for long enough, s.mu must be held
+func (s *supervisor) checkTrial() {
+	b := s.policy.Breaker
+	if b == nil || s.state != BreakerHalfOpen {
+		return
+	}
+	if time.Since(s.runStart) >= b.Cooldown {
+		s.state = BreakerClosed
+		s.failures = 0
+	}
+}
@@ -0,0 +1,65 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func waitBreaker(t *testing.T, s Supervised, state BreakerState) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for s.BreakerState() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("breaker should be %v but is %v", state, s.BreakerState())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	runs := make(chan chan error)
+	branch := localRoot.BranchSupervised(func(b Tree) error {
+		result := make(chan error)
+		runs <- result
+		select {
+		case err := <-result:
+			return err
+		case <-b.Pruned():
+			return nil
+		}
+	}, RestartPolicy{Breaker: &BreakerPolicy{Failures: 2, Cooldown: time.Millisecond * 100}})
+
+	failure := errors.New("down")
+	(<-runs) <- failure
+	(<-runs) <- failure
+	waitBreaker(t, branch, BreakerOpen)
+
+	select {
+	case <-runs:
+		t.Fatal("no restart should happen while the breaker is open")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	// trial fails, breaker opens again
+	(<-runs) <- failure
+	waitBreaker(t, branch, BreakerOpen)
+
+	trial := <-runs
+	if branch.BreakerState() != BreakerHalfOpen {
+		t.Fatalf("breaker should be half-open, got %v", branch.BreakerState())
+	}
+	waitBreaker(t, branch, BreakerClosed)
+	trial <- failure
+
+	// after a healthy run, a single failure doesn't open the breaker
+	next := <-runs
+	if branch.BreakerState() != BreakerClosed {
+		t.Fatalf("breaker should be closed, got %v", branch.BreakerState())
+	}
+	next <- nil
+	<-branch.Done()
+}
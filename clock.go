@@ -0,0 +1,39 @@
+package jungle
+
+import "time"
+
+type (
+	// Clock tells the time, it can be replaced with WithClock to control
+	// the measurements taken by a root in tests
+	Clock interface {
+		Now() time.Time
+	}
+)
+
+// WithClock makes the root use c to measure how long things take, eg.:
+// LastPruneDuration and the quiet period of Quiesce. The default is the system clock. Timers, like the
+// ones used for deadlines, are not affected.
+func WithClock(c Clock) RootOption {
+	return func(rc *rootConfig) {
+		rc.clock = c
+	}
+}
+
+// now returns the time from the configured clock
+func (c *rootConfig) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// LastPruneDuration returns how long the last prune of the root of t took,
+// from the moment its lifecycle got the prune signal until the root was
+// done, including waiting for all of its descendants. It returns zero
+// while the root is not done, eg.: for Root, which is never pruned.
+//
+// A slow shutdown usually means some process function doesn't watch
+// Pruned, see also PruneWatchdog.
+func (t *tree) LastPruneDuration() time.Duration {
+	return time.Duration(t.root.lastPrune.Load())
+}
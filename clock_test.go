@@ -0,0 +1,59 @@
+package jungle
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	// stepClock moves forward by step every time it is read
+	stepClock struct {
+		sync.Mutex
+		now  time.Time
+		step time.Duration
+	}
+)
+
+func (c *stepClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func TestLastPruneDuration(t *testing.T) {
+	root := New()
+	ready := make(chan Signal, 2)
+	for i := 0; i < 2; i++ {
+		root.Branch().BranchFunc(func(b Tree) error {
+			ready <- Signal{}
+			<-b.Pruned()
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+	}
+	<-ready
+	<-ready
+	if d := root.LastPruneDuration(); d != 0 {
+		t.Fatalf("should be zero before the prune, got %v", d)
+	}
+	root.Prune()
+	<-root.Done()
+	if d := root.LastPruneDuration(); d < 20*time.Millisecond {
+		t.Fatalf("should take at least as long as the slowest child, got %v", d)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	root := New(WithClock(&stepClock{step: time.Minute}))
+	child := root.Branch()
+	root.Prune()
+	<-root.Done()
+	if d := root.LastPruneDuration(); d != time.Minute {
+		t.Fatalf("expecting one step of the clock got %v", d)
+	}
+	if d := child.LastPruneDuration(); d != time.Minute {
+		t.Fatalf("branches should report the prune of their root, got %v", d)
+	}
+}
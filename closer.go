@@ -0,0 +1,13 @@
+package jungle
+
+import "io"
+
+// RegisterCloser closes c as soon as t is pruned, which is the usual way to
+// unblock a process function waiting on c (eg.: a listener or connection).
+// The error returned by Close is ignored.
+func RegisterCloser(t Tree, c io.Closer) {
+	go func() {
+		<-t.Pruned()
+		c.Close()
+	}()
+}
@@ -0,0 +1,27 @@
+package jungle
+
+import (
+	"testing"
+	"time"
+)
+
+type closerFunc func() error
+
+func (fn closerFunc) Close() error {
+	return fn()
+}
+
+func TestRegisterCloser(t *testing.T) {
+	closed := make(chan Signal)
+	branch := Root().Branch()
+	RegisterCloser(branch, closerFunc(func() error {
+		close(closed)
+		return nil
+	}))
+	branch.Prune()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("closer should be called once the tree is pruned")
+	}
+}
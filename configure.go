@@ -0,0 +1,90 @@
+package jungle
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+type (
+	// Option changes the global configuration applied by Configure
+	Option func(*globalConfig)
+
+	globalConfig struct {
+		logger        Logger
+		metrics       Metrics
+		admit         func() bool
+		captureStacks bool
+	}
+)
+
+var (
+	// ErrConfigured is returned when Configure is called more than once
+	ErrConfigured = errors.New("jungle: already configured")
+
+	configured atomic.Bool
+)
+
+// Configure applies all the global settings at once, it is meant to be
+// called once at program start, before any branch is created. Later calls
+// return ErrConfigured without changing anything, so the configuration
+// can't change under a running program.
+//
+// Settings which are not given keep their defaults. The individual SetX
+// functions are still available, eg.: for tests.
+//
+// Only process wide settings are covered. The clock is chosen per root
+// with WithClock, and the prune watchdog (PruneWatchdog) and leak
+// detection (StrictScope) are applied per call, so none of them have an
+// Option here.
+func Configure(opts ...Option) error {
+	if !configured.CompareAndSwap(false, true) {
+		return ErrConfigured
+	}
+	var c globalConfig
+	for _, o := range opts {
+		o(&c)
+	}
+	if c.logger != nil {
+		SetLogger(c.logger)
+	}
+	if c.metrics != nil {
+		SetMetrics(c.metrics)
+	}
+	if c.admit != nil {
+		SetAdmissionController(c.admit)
+	}
+	if c.captureStacks {
+		SetCaptureBranchStacks(true)
+	}
+	return nil
+}
+
+// WithLogger configures the Logger, see SetLogger
+func WithLogger(l Logger) Option {
+	return func(c *globalConfig) {
+		c.logger = l
+	}
+}
+
+// WithMetrics configures the Metrics, see SetMetrics
+func WithMetrics(m Metrics) Option {
+	return func(c *globalConfig) {
+		c.metrics = m
+	}
+}
+
+// WithAdmissionController configures the admission controller, see
+// SetAdmissionController
+func WithAdmissionController(admit func() bool) Option {
+	return func(c *globalConfig) {
+		c.admit = admit
+	}
+}
+
+// WithBranchStacks enables the capture of branch stacks used by the prune
+// watchdog, see SetCaptureBranchStacks
+func WithBranchStacks() Option {
+	return func(c *globalConfig) {
+		c.captureStacks = true
+	}
+}
@@ -0,0 +1,47 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+)
+
+type countingLogger struct {
+	events chan Event
+}
+
+func (l countingLogger) LogEvent(_ uint64, _ string, e Event) {
+	select {
+	case l.events <- e:
+	default:
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer func() {
+		SetLogger(nil)
+		SetAdmissionController(nil)
+		configured.Store(false)
+	}()
+	logger := countingLogger{events: make(chan Event, 10)}
+	err := Configure(
+		WithLogger(logger),
+		WithAdmissionController(func() bool { return false }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	branch := Root().Branch()
+	if !errors.Is(branch.Cause(), ErrNotAdmitted) {
+		t.Fatalf("admission controller should be configured, got %v", branch.Cause())
+	}
+	if len(logger.events) == 0 {
+		t.Fatal("logger should be configured")
+	}
+
+	if err := Configure(WithLogger(nil)); !errors.Is(err, ErrConfigured) {
+		t.Fatalf("expecting ErrConfigured got %v", err)
+	}
+	if currentLogger() != Logger(logger) {
+		t.Fatal("a failed Configure should not change anything")
+	}
+}
@@ -0,0 +1,121 @@
+package jungle
+
+import (
+	"context"
+	"time"
+)
+
+// BranchFuncCtx works like BranchFunc but fn also receives a context that
+// is canceled when the branch is pruned.
+//
+// If the branch (or any of its ancestors) has a deadline, the context reports
+// it from Deadline and fails with context.DeadlineExceeded once it is reached.
+//
+// The context carries the values of the context given to BranchContext or
+// Adopt by the closest ancestor, eg.: a tracing span.
+//
+// Under a root with WithGoroutineLabels, the context carries the same
+// labels as the goroutine, see pprof.Label.
+func (t *tree) BranchFuncCtx(fn func(context.Context, Tree) error) Tree {
+	return t.BranchFunc(func(branch Tree) error {
+		ctx, cancel := branch.(*tree).context(branch.(*tree).labels(branch.(*tree).valuesContext()))
+		defer cancel()
+		return fn(ctx, branch)
+	})
+}
+
+// WithCancelCause returns a context bound to t: it is canceled when t is
+// pruned, and calling cancel prunes t using the given cause, which is also
+// reported by context.Cause.
+//
+// This is useful to trigger the shutdown of a tree from code deep down
+// which only has access to a context. Like BranchFuncCtx, the context
+// carries the values given to BranchContext or Adopt.
+func (t *tree) WithCancelCause() (context.Context, context.CancelCauseFunc) {
+	base, stop := t.context(t.valuesContext())
+	ctx, cancel := context.WithCancelCause(base)
+	go func() {
+		defer stop()
+		<-ctx.Done()
+		t.PruneWith(context.Cause(ctx))
+	}()
+	return ctx, cancel
+}
+
+// BranchContext creates a new branch whose contexts, from BranchFuncCtx
+// and WithCancelCause, carry the values of ctx, eg.: a tracing span, so
+// they are not lost when a jungle node sits between two pieces of
+// context-aware code. Descendants of the branch inherit the values, unless
+// they call BranchContext themselves.
+//
+// Only the values are taken, the branch is not pruned when ctx is done,
+// see Adopt for that.
+func (t *tree) BranchContext(ctx context.Context) Tree {
+	branch := newTree(t, nil)
+	branch.ctxValues = ctx
+	return t.grow(branch)
+}
+
+// valuesContext returns a context with the values of t, which is never
+// canceled
+func (t *tree) valuesContext() context.Context {
+	if t.ctxValues == nil {
+		return context.Background()
+	}
+	return context.WithoutCancel(t.ctxValues)
+}
+
+// Adopt wraps ctx as a new branch of Root which is pruned as soon as ctx
+// is done, so context-first code can hang subtrees from an existing
+// request context. Like BranchContext, the values of ctx are carried by
+// the contexts derived from the branch.
+//
+// If ctx is already done, the returned tree is already done as well.
+func Adopt(ctx context.Context) Tree {
+	branch := rootTree.BranchContext(ctx)
+	select {
+	case <-ctx.Done():
+		branch.Prune()
+		<-branch.Done()
+		return branch
+	default:
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			branch.Prune()
+		case <-branch.Pruned():
+		}
+	}()
+	return branch
+}
+
+// context derives a context from parent which is canceled when t
+// is pruned or reaches its deadline
+func (t *tree) context(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	deadline, hasDeadline := t.effectiveDeadline()
+	if hasDeadline {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, deadline)
+		cancelParent := cancel
+		cancel = func() {
+			cancelDeadline()
+			cancelParent()
+		}
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-t.prune:
+			if hasDeadline && !time.Now().Before(deadline) {
+				// the prune was caused by the deadline, let the context
+				// report context.DeadlineExceeded instead of context.Canceled
+				<-ctx.Done()
+				return
+			}
+			cancel()
+		}
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,144 @@
+package jungle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBranchFuncCtxPrune(t *testing.T) {
+	localRoot := Root().Branch()
+	errs := make(chan error, 1)
+	localRoot.BranchFuncCtx(func(ctx context.Context, _ Tree) error {
+		<-ctx.Done()
+		errs <- ctx.Err()
+		return nil
+	})
+	localRoot.Prune()
+	<-localRoot.Done()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("expecting context.Canceled got %v", err)
+	}
+}
+
+func TestBranchFuncCtxDeadline(t *testing.T) {
+	deadline := time.Now().Add(time.Millisecond * 100)
+	timed := Root().BranchDeadline(deadline)
+	errs := make(chan error, 1)
+	timed.Branch().BranchFuncCtx(func(ctx context.Context, _ Tree) error {
+		if d, ok := ctx.Deadline(); !ok || !d.Equal(deadline) {
+			t.Errorf("context deadline should be %v got %v", deadline, d)
+		}
+		<-ctx.Done()
+		errs <- ctx.Err()
+		return nil
+	})
+	select {
+	case <-timed.Done():
+	case <-time.After(time.Second):
+		t.Fatal("branch should be pruned at the deadline")
+	}
+	if err := <-errs; err != context.DeadlineExceeded {
+		t.Fatalf("expecting context.DeadlineExceeded got %v", err)
+	}
+	if time.Now().Before(deadline) {
+		t.Fatal("branch was pruned before the deadline")
+	}
+}
+
+func TestAdopt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	adopted := Adopt(ctx)
+	child := adopted.Branch()
+	select {
+	case <-adopted.Pruned():
+		t.Fatal("tree should be alive while the context is alive")
+	default:
+	}
+	cancel()
+	select {
+	case <-child.Done():
+	case <-time.After(time.Second):
+		t.Fatal("children should be pruned when the context is canceled")
+	}
+	<-adopted.Done()
+}
+
+func TestAdoptCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	adopted := Adopt(ctx)
+	select {
+	case <-adopted.Done():
+	default:
+		t.Fatal("adopting a canceled context should return a done tree")
+	}
+}
+
+func TestWithCancelCause(t *testing.T) {
+	branch := Root().Branch()
+	child := branch.Branch()
+	ctx, cancel := branch.WithCancelCause()
+	cause := errors.New("fatal")
+	cancel(cause)
+	select {
+	case <-child.Done():
+	case <-time.After(time.Second):
+		t.Fatal("cancel should prune the tree")
+	}
+	<-branch.Done()
+	if branch.Cause() != cause {
+		t.Fatalf("expecting cause %v got %v", cause, branch.Cause())
+	}
+	if context.Cause(ctx) != cause {
+		t.Fatalf("expecting context cause %v got %v", cause, context.Cause(ctx))
+	}
+
+	other := Root().Branch()
+	ctx, cancel = other.WithCancelCause()
+	defer cancel(nil)
+	other.Prune()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("prune should cancel the context")
+	}
+}
+
+type spanKey struct{}
+
+func TestBranchContextValues(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), spanKey{}, "span-1"))
+	traced := localRoot.BranchContext(parent)
+	cancel()
+	spans := make(chan interface{}, 1)
+	traced.Branch().BranchFuncCtx(func(ctx context.Context, _ Tree) error {
+		spans <- ctx.Value(spanKey{})
+		return ctx.Err()
+	})
+	if span := <-spans; span != "span-1" {
+		t.Fatalf("the span should reach the context of descendants, got %v", span)
+	}
+	if traced.IsPruned() {
+		t.Fatal("BranchContext should only take the values of ctx")
+	}
+
+	ctx, stop := traced.WithCancelCause()
+	defer stop(nil)
+	if span := ctx.Value(spanKey{}); span != "span-1" {
+		t.Fatalf("WithCancelCause should carry the span, got %v", span)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("cancelling the original context should not cancel the tree context, got %v", ctx.Err())
+	}
+
+	adopted := Adopt(parent)
+	<-adopted.Done()
+	if adopted.(*tree).valuesContext().Value(spanKey{}) != "span-1" {
+		t.Fatal("Adopt should carry the values of ctx")
+	}
+}
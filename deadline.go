@@ -0,0 +1,119 @@
+package jungle
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type (
+	deadlineExceeded struct{}
+)
+
+var (
+	// ErrDeadlineExceeded is the cause used to prune a branch which reached
+	// its deadline or max lifetime, it matches context.DeadlineExceeded
+	// with errors.Is
+	ErrDeadlineExceeded error = deadlineExceeded{}
+)
+
+// BranchDeadline creates a new branch that is pruned as soon as the
+// given deadline is reached, along with all of its children.
+//
+// The timer is stopped if the branch finishes before the deadline. Once
+// the deadline prunes the branch, Err returns ErrDeadlineExceeded.
+func (t *tree) BranchDeadline(deadline time.Time) Tree {
+	branch := newTree(t, nil)
+	branch.deadline = deadline
+	return t.grow(branch)
+}
+
+// BranchMaxLifetime creates a new branch that is pruned once d has passed
+// since its creation, regardless of activity. Useful to recycle workers
+// periodically.
+//
+// If the branch also inherits a deadline, whichever comes first prunes it.
+func (t *tree) BranchMaxLifetime(d time.Duration) Tree {
+	branch := newTree(t, nil)
+	branch.lifetime = d
+	return t.grow(branch)
+}
+
+// BranchFuncTimeout creates a new branch running fn, if fn doesn't return
+// within d after it started the branch is pruned with ErrDeadlineExceeded.
+//
+// Unlike BranchDeadline, the time is counted from the moment fn starts
+// running, not from the creation of the branch. fn must watch Pruned to
+// actually stop, once it returns Err reports ErrDeadlineExceeded unless fn
+// returned an error of its own.
+func (t *tree) BranchFuncTimeout(fn func(Tree) error, d time.Duration) Tree {
+	return t.BranchFunc(func(branch Tree) error {
+		timer := time.AfterFunc(d, func() {
+			branch.PruneWith(ErrDeadlineExceeded)
+		})
+		defer timer.Stop()
+		return fn(branch)
+	})
+}
+
+// BranchChildTimeout creates a new branch running fn, d after fn starts
+// the children of the branch are pruned with ErrDeadlineExceeded but fn
+// itself keeps running, so it can handle the timeout, eg.: by writing a
+// timeout response.
+//
+// Only the children alive at the timeout are pruned, fn can check if that
+// happened via their TimedOut method.
+func (t *tree) BranchChildTimeout(fn func(Tree) error, d time.Duration) Tree {
+	return t.BranchFunc(func(branch Tree) error {
+		timer := time.AfterFunc(d, func() {
+			for _, c := range branch.(*tree).children() {
+				c.PruneWith(ErrDeadlineExceeded)
+			}
+		})
+		defer timer.Stop()
+		return fn(branch)
+	})
+}
+
+// expiresAt returns the moment t should be pruned due to its own deadline
+// or max lifetime, whichever comes first
+func (t *tree) expiresAt() (time.Time, bool) {
+	at := t.deadline
+	if t.lifetime > 0 {
+		end := t.created.Add(t.lifetime)
+		if at.IsZero() || end.Before(at) {
+			at = end
+		}
+	}
+	return at, !at.IsZero()
+}
+
+// effectiveDeadline returns the earliest expiration among t and its
+// ancestors, since any of them being pruned also prunes t
+func (t *tree) effectiveDeadline() (time.Time, bool) {
+	var deadline time.Time
+	for p := t; p != nil; p = p.parent {
+		at, ok := p.expiresAt()
+		if !ok {
+			continue
+		}
+		if deadline.IsZero() || at.Before(deadline) {
+			deadline = at
+		}
+	}
+	return deadline, !deadline.IsZero()
+}
+
+// TimedOut returns true if this tree was pruned by its own deadline, max
+// lifetime or timeout, instead of a regular prune
+func (t *tree) TimedOut() bool {
+	return errors.Is(t.Cause(), ErrDeadlineExceeded)
+}
+
+func (deadlineExceeded) Error() string {
+	return "jungle: deadline exceeded"
+}
+
+func (deadlineExceeded) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
@@ -0,0 +1,116 @@
+package jungle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBranchMaxLifetime(t *testing.T) {
+	start := time.Now()
+	branch := Root().BranchMaxLifetime(time.Millisecond * 100)
+	select {
+	case <-branch.Done():
+	case <-time.After(time.Second):
+		t.Fatal("branch should be pruned after its lifetime")
+	}
+	if elapsed := time.Since(start); elapsed < time.Millisecond*100 {
+		t.Fatalf("branch was pruned too early, after %v", elapsed)
+	}
+}
+
+func TestMaxLifetimeWithDeadline(t *testing.T) {
+	deadline := Root().BranchDeadline(time.Now().Add(time.Millisecond * 50))
+	long := deadline.BranchMaxLifetime(time.Hour)
+	if d, ok := long.(*tree).effectiveDeadline(); !ok || time.Until(d) > time.Minute {
+		t.Fatalf("the earliest deadline should win, got %v", d)
+	}
+	select {
+	case <-long.Done():
+	case <-time.After(time.Second):
+		t.Fatal("branch should be pruned at the parent deadline")
+	}
+
+	parent := Root().BranchDeadline(time.Now().Add(time.Hour))
+	defer parent.Prune()
+	short := parent.BranchMaxLifetime(time.Millisecond * 50)
+	select {
+	case <-short.Done():
+	case <-time.After(time.Second):
+		t.Fatal("branch should be pruned at its lifetime")
+	}
+}
+
+func TestDeadlineErr(t *testing.T) {
+	branch := Root().BranchDeadline(time.Now().Add(time.Millisecond * 20))
+	child := branch.Branch()
+	<-branch.Done()
+	if !branch.TimedOut() || !errors.Is(branch.Err(), ErrDeadlineExceeded) {
+		t.Fatalf("expecting ErrDeadlineExceeded got %v", branch.Err())
+	}
+	if !errors.Is(branch.Err(), context.DeadlineExceeded) {
+		t.Fatal("ErrDeadlineExceeded should match context.DeadlineExceeded")
+	}
+	if child.TimedOut() || child.Err() != nil {
+		t.Fatalf("children are pruned as usual, got %v", child.Err())
+	}
+
+	clean := Root().BranchDeadline(time.Now().Add(time.Hour))
+	clean.Prune()
+	<-clean.Done()
+	if clean.TimedOut() || clean.Err() != nil {
+		t.Fatalf("a regular prune should not time out, got %v", clean.Err())
+	}
+}
+
+func TestBranchFuncTimeout(t *testing.T) {
+	slow := Root().BranchFuncTimeout(func(t Tree) error {
+		<-t.Pruned()
+		return nil
+	}, 20*time.Millisecond)
+	select {
+	case <-slow.Done():
+	case <-time.After(time.Second):
+		t.Fatal("slow function should be timed out")
+	}
+	if !slow.TimedOut() || !errors.Is(slow.Err(), ErrDeadlineExceeded) {
+		t.Fatalf("expecting ErrDeadlineExceeded got %v", slow.Err())
+	}
+
+	fast := Root().BranchFuncTimeout(func(Tree) error { return nil }, time.Second)
+	<-fast.Done()
+	if fast.TimedOut() || fast.Err() != nil {
+		t.Fatalf("fast function should not time out, got %v", fast.Err())
+	}
+}
+
+func TestBranchChildTimeout(t *testing.T) {
+	handled := make(chan error, 1)
+	branch := Root().BranchChildTimeout(func(t Tree) error {
+		sub := t.BranchFunc(func(t Tree) error {
+			<-t.Pruned()
+			return nil
+		})
+		<-sub.Done()
+		if !sub.TimedOut() {
+			handled <- errors.New("child should time out")
+			return nil
+		}
+		// the process is still running after the timeout
+		handled <- nil
+		return nil
+	}, 20*time.Millisecond)
+	select {
+	case err := <-handled:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("children should be pruned at the timeout")
+	}
+	<-branch.Done()
+	if branch.TimedOut() || branch.Err() != nil {
+		t.Fatalf("the branch itself should not time out, got %v", branch.Err())
+	}
+}
@@ -0,0 +1,190 @@
+package jungle
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+type (
+	// PruneOrder defines how a tree prunes its children, dependencies
+	// declared with DependsOn are always respected first
+	PruneOrder int
+)
+
+const (
+	// PruneConcurrent sends the prune signal to the children in the order
+	// they were created (oldest first) without waiting for them, so they
+	// finish concurrently, in no particular order. This is the default.
+	PruneConcurrent PruneOrder = iota
+	// PruneFIFO prunes one child at a time, oldest first, each child is
+	// done before the next one receives the prune signal
+	PruneFIFO
+	// PruneLIFO prunes one child at a time, newest first, each child is
+	// done before the next one receives the prune signal
+	PruneLIFO
+)
+
+var (
+	// ErrDependencyCycle is returned by DependsOn if the new dependency
+	// would create a cycle
+	ErrDependencyCycle = errors.New("jungle: dependency cycle")
+
+	// ErrNotSibling is returned by DependsOn when the trees don't share
+	// the same parent
+	ErrNotSibling = errors.New("jungle: trees are not siblings")
+
+	// depsMu guards the dependencies of all trees, so cycle detection
+	// sees a consistent graph
+	depsMu sync.Mutex
+)
+
+// DependsOn declares that t uses other, so whenever their parent is pruned,
+// t is pruned and waited upon before other is pruned.
+//
+// Both trees must share the same parent, otherwise ErrNotSibling is returned,
+// or ErrCrossRoot if they don't even belong to the same root.
+// A dependency that would create a cycle is rejected with ErrDependencyCycle.
+//
+// The dependency is dropped once other is done, as there is nothing left
+// to order.
+func (t *tree) DependsOn(other Tree) error {
+	o, ok := other.(*tree)
+	if !ok {
+		return ErrNotSibling
+	}
+	if err := sameRoot(t, o); err != nil {
+		return err
+	}
+	if o.parent != t.parent || t.parent == nil {
+		return ErrNotSibling
+	}
+	depsMu.Lock()
+	defer depsMu.Unlock()
+	if o == t || o.reaches(t) {
+		return ErrDependencyCycle
+	}
+	t.deps = append(t.deps, o)
+	o.Defer(func() { t.dropDep(o) })
+	return nil
+}
+
+// dropDep removes o from the dependencies of t
+func (t *tree) dropDep(o *tree) {
+	depsMu.Lock()
+	defer depsMu.Unlock()
+	for i, d := range t.deps {
+		if d == o {
+			t.deps = append(t.deps[:i], t.deps[i+1:]...)
+			return
+		}
+	}
+}
+
+// sameRoot returns ErrCrossRoot if a and b belong to different roots
+func sameRoot(a, b *tree) error {
+	if a.root != b.root {
+		return ErrCrossRoot
+	}
+	return nil
+}
+
+// reaches returns true if target is among the (transitive) dependencies
+// of t, depsMu must be held by the caller
+func (t *tree) reaches(target *tree) bool {
+	for _, d := range t.deps {
+		if d == target || d.reaches(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneAll prunes all trees in s respecting their dependencies, trees that
+// are not used by anyone else are pruned first, and only after they are done
+// their dependencies are pruned.
+//
+// The last group is not waited upon, the caller is responsible for that.
+//
+// With prune waves configured, each group is pruned a few trees at a time.
+// With PruneFIFO or PruneLIFO each group is pruned one tree at a time, and
+// every tree is waited upon, including the ones in the last group.
+//
+// If teardown fires while waiting, every tree left is pruned right away and
+// false is returned, so the caller can abandon them.
+func (s *subtrees) pruneAll(config *rootConfig, teardown <-chan time.Time) bool {
+	pending := append(subtrees(nil), *s...)
+	for len(pending) > 0 {
+		used := make(map[*tree]bool)
+		depsMu.Lock()
+		for _, c := range pending {
+			for _, d := range c.deps {
+				used[d] = true
+			}
+		}
+		depsMu.Unlock()
+
+		var group, next subtrees
+		for _, c := range pending {
+			if used[c] {
+				next = append(next, c)
+			} else {
+				group = append(group, c)
+			}
+		}
+		if len(group) == 0 {
+			// should never happen as cycles are rejected,
+			// but lets not hang because of it
+			group, next = next, nil
+		}
+		switch config.pruneOrder {
+		case PruneFIFO, PruneLIFO:
+			for i := range group {
+				c := group[i]
+				if config.pruneOrder == PruneLIFO {
+					c = group[len(group)-1-i]
+				}
+				c.Prune()
+				if !waitDone(c, teardown) {
+					pending.pruneNow()
+					return false
+				}
+			}
+			pending = next
+			continue
+		}
+		for i, c := range group {
+			if config.pruneWave > 0 && i > 0 && i%config.pruneWave == 0 {
+				time.Sleep(config.pruneWaveInterval)
+			}
+			c.Prune()
+		}
+		if len(next) > 0 {
+			for _, c := range group {
+				if !waitDone(c, teardown) {
+					pending.pruneNow()
+					return false
+				}
+			}
+		}
+		pending = next
+	}
+	return true
+}
+
+// waitDone waits until c is done, returning false if teardown fires first
+func waitDone(c *tree, teardown <-chan time.Time) bool {
+	select {
+	case <-c.Done():
+		return true
+	case <-teardown:
+		return false
+	}
+}
+
+// pruneNow prunes all trees in s without waiting for anything
+func (s subtrees) pruneNow() {
+	for _, c := range s {
+		c.Prune()
+	}
+}
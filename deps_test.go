@@ -0,0 +1,100 @@
+package jungle
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDependsOn(t *testing.T) {
+	localRoot := Root().Branch()
+	var mu sync.Mutex
+	var order []string
+	branch := func(name string) Tree {
+		return localRoot.BranchFunc(func(b Tree) error {
+			<-b.Pruned()
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		})
+	}
+	db := branch("db")
+	cache := branch("cache")
+	api := branch("api")
+	worker := branch("worker")
+
+	for _, dep := range []struct{ from, to Tree }{
+		{api, cache}, {api, db}, {cache, db}, {worker, db},
+	} {
+		if err := dep.from.DependsOn(dep.to); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.DependsOn(api); err != ErrDependencyCycle {
+		t.Fatalf("should detect the cycle but got %v", err)
+	}
+	if err := db.DependsOn(db); err != ErrDependencyCycle {
+		t.Fatalf("should detect the self-dependency but got %v", err)
+	}
+	stranger := Root().Branch()
+	defer stranger.Prune()
+	if err := db.DependsOn(stranger); err != ErrNotSibling {
+		t.Fatalf("should reject non-siblings but got %v", err)
+	}
+
+	localRoot.Prune()
+	<-localRoot.Done()
+
+	pos := make(map[string]int)
+	for i, n := range order {
+		pos[n] = i
+	}
+	if len(pos) != 4 {
+		t.Fatalf("all branches should be pruned, got %v", order)
+	}
+	if pos["api"] > pos["cache"] || pos["cache"] > pos["db"] || pos["worker"] > pos["db"] {
+		t.Fatalf("invalid prune order %v", order)
+	}
+}
+
+func TestDependsOnDropsDoneDependencies(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	api := localRoot.Branch()
+	db := localRoot.Branch()
+	if err := api.DependsOn(db); err != nil {
+		t.Fatal(err)
+	}
+	db.Prune()
+	<-db.Done()
+	deadline := time.Now().Add(time.Second)
+	for {
+		depsMu.Lock()
+		n := len(api.(*tree).deps)
+		depsMu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("a dependency should be dropped once it is done")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDependsOnCrossRoot(t *testing.T) {
+	a := New()
+	b := New()
+	defer a.Prune()
+	defer b.Prune()
+	if err := a.Branch().DependsOn(b.Branch()); err != ErrCrossRoot {
+		t.Fatalf("expecting ErrCrossRoot got %v", err)
+	}
+	local := Root().Branch()
+	defer local.Prune()
+	if err := local.DependsOn(a.Branch()); err != ErrCrossRoot {
+		t.Fatalf("expecting ErrCrossRoot got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package jungle
+
+import "sort"
+
+type (
+	// TreeDiff lists what changed between two snapshots, branches are
+	// matched by PID. Children are not included in the entries.
+	TreeDiff struct {
+		// Added are branches only present in the second snapshot
+		Added []TreeSnapshot
+		// Removed are branches only present in the first snapshot
+		Removed []TreeSnapshot
+		// Changed are branches present in both snapshots whose name, tags
+		// or state changed
+		Changed []SnapshotChange
+	}
+
+	// SnapshotChange holds the two versions of a changed branch
+	SnapshotChange struct {
+		Before TreeSnapshot
+		After  TreeSnapshot
+	}
+)
+
+// Diff compares two snapshots of the same tree, entries are sorted by PID.
+func Diff(before, after TreeSnapshot) TreeDiff {
+	old, cur := before.flatten(), after.flatten()
+	var d TreeDiff
+	for pid, a := range cur {
+		b, ok := old[pid]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, a)
+		case !sameSnapshot(b, a):
+			d.Changed = append(d.Changed, SnapshotChange{Before: b, After: a})
+		}
+	}
+	for pid, b := range old {
+		if _, ok := cur[pid]; !ok {
+			d.Removed = append(d.Removed, b)
+		}
+	}
+	sortByPID(d.Added)
+	sortByPID(d.Removed)
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].After.PID < d.Changed[j].After.PID })
+	return d
+}
+
+// Empty returns true if nothing changed
+func (d TreeDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// flatten returns s and all of its descendants by PID, without children
+func (s TreeSnapshot) flatten() map[uint64]TreeSnapshot {
+	nodes := make(map[uint64]TreeSnapshot)
+	var visit func(TreeSnapshot)
+	visit = func(s TreeSnapshot) {
+		children := s.Children
+		s.Children = nil
+		nodes[s.PID] = s
+		for _, c := range children {
+			visit(c)
+		}
+	}
+	visit(s)
+	return nodes
+}
+
+func sameSnapshot(a, b TreeSnapshot) bool {
+	if a.Name != b.Name || a.State != b.State || len(a.Tags) != len(b.Tags) {
+		return false
+	}
+	for k, v := range a.Tags {
+		if bv, ok := b.Tags[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sortByPID(s []TreeSnapshot) {
+	sort.Slice(s, func(i, j int) bool { return s[i].PID < s[j].PID })
+}
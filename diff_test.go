@@ -0,0 +1,38 @@
+package jungle
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	root := Root().Branch()
+	defer root.Prune()
+	kept := root.Branch()
+	removed := root.Branch()
+	before := Snapshot(root)
+
+	kept.SetName("renamed")
+	removed.Prune()
+	<-removed.Done()
+	for len(root.(*tree).children()) > 1 {
+		// wait for the parent to notice
+		runtime.Gosched()
+	}
+	added := kept.Branch()
+	after := Snapshot(root)
+
+	d := Diff(before, after)
+	if len(d.Added) != 1 || d.Added[0].PID != added.PID() {
+		t.Fatalf("expecting %v to be added got %v", added.PID(), d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].PID != removed.PID() {
+		t.Fatalf("expecting %v to be removed got %v", removed.PID(), d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].Before.Name != "" || d.Changed[0].After.Name != "renamed" {
+		t.Fatalf("expecting %v to be renamed got %v", kept.PID(), d.Changed)
+	}
+	if !Diff(after, after).Empty() {
+		t.Fatal("a snapshot should not differ from itself")
+	}
+}
@@ -0,0 +1,37 @@
+package jungle
+
+// Drain pauses this tree so it doesn't accept new branches, waits until the
+// current branches finish on their own (they are not pruned) and then
+// prunes the tree, returning once it is done.
+func (t *tree) Drain() {
+	t.DrainWithProgress(nil)
+}
+
+// DrainWithProgress works like Drain, but fn is called with the number of
+// branches still running each time one of them finishes, eg.: to show
+// "waiting for N tasks". fn is called from the goroutine calling
+// DrainWithProgress, and always a last time with 0 before the tree is
+// pruned.
+func (t *tree) DrainWithProgress(fn func(remaining int)) {
+	t.Pause()
+	branches := t.children()
+	finished := make(chan Signal, len(branches))
+	for _, c := range branches {
+		go func(c *tree) {
+			<-c.done
+			finished <- Signal{}
+		}(c)
+	}
+	for remaining := len(branches); remaining > 0; {
+		<-finished
+		remaining--
+		if fn != nil && remaining > 0 {
+			fn(remaining)
+		}
+	}
+	if fn != nil {
+		fn(0)
+	}
+	t.Prune()
+	<-t.done
+}
@@ -0,0 +1,41 @@
+package jungle
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDrainWithProgress(t *testing.T) {
+	parent := Root().Branch()
+	var interrupted atomic.Bool
+	for i := 1; i <= 3; i++ {
+		d := time.Duration(i) * 10 * time.Millisecond
+		parent.BranchFunc(func(t Tree) error {
+			select {
+			case <-time.After(d):
+			case <-t.Pruned():
+				interrupted.Store(true)
+			}
+			return nil
+		})
+	}
+	var counts []int
+	parent.DrainWithProgress(func(remaining int) {
+		if parent.IsPruned() {
+			t.Error("callback should run before the tree is pruned")
+		}
+		counts = append(counts, remaining)
+	})
+	if len(counts) != 3 || counts[0] != 2 || counts[1] != 1 || counts[2] != 0 {
+		t.Fatalf("expecting 2, 1, 0 got %v", counts)
+	}
+	if interrupted.Load() {
+		t.Fatal("branches should finish on their own")
+	}
+	select {
+	case <-parent.Done():
+	default:
+		t.Fatal("tree should be done after Drain")
+	}
+}
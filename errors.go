@@ -0,0 +1,23 @@
+package jungle
+
+import "fmt"
+
+type (
+	// BranchError wraps an error returned by the process function of a
+	// branch with the identity of that branch, it is used whenever errors
+	// from many branches are aggregated, eg.: PruneCollect.
+	BranchError struct {
+		PID  uint64
+		Name string
+		Err  error
+	}
+)
+
+func (e *BranchError) Error() string {
+	return fmt.Sprintf("jungle: branch %v (%v): %v", e.PID, e.Name, e.Err)
+}
+
+// Unwrap returns the error from the process function
+func (e *BranchError) Unwrap() error {
+	return e.Err
+}
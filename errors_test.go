@@ -0,0 +1,31 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBranchError(t *testing.T) {
+	root := New()
+	failure := errors.New("failure")
+	var failing Tree
+	ready := make(chan Signal)
+	failing = root.BranchFunc(func(b Tree) error {
+		b.SetName("worker")
+		close(ready)
+		<-b.Pruned()
+		return failure
+	})
+	<-ready
+	errs := root.PruneCollect()
+	if len(errs) != 1 {
+		t.Fatalf("expecting 1 error got %v", errs)
+	}
+	var berr *BranchError
+	if !errors.As(errs[0], &berr) {
+		t.Fatalf("expecting a BranchError got %T", errs[0])
+	}
+	if berr.PID != failing.PID() || berr.Name != "worker" || !errors.Is(berr, failure) {
+		t.Fatalf("unexpected BranchError %+v", berr)
+	}
+}
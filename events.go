@@ -0,0 +1,91 @@
+package jungle
+
+type (
+	// EventKind identifies what happened to a tree
+	EventKind int
+
+	// Event describes a change in the lifecycle of a tree
+	Event struct {
+		Kind EventKind
+		PID  uint64
+
+		// Cause is the reason why the tree was pruned, if any
+		Cause error
+
+		// Tags of the tree when the event happened
+		Tags map[string]string
+	}
+)
+
+const (
+	// PruneStarted is sent as soon as the tree receives the prune signal
+	PruneStarted EventKind = iota + 1
+	// Pruned is sent after the tree and all of its children are done
+	Pruned
+	// Abandoned is only sent to the Logger, for each branch still running
+	// when its parent gave up waiting after the teardown timeout, with
+	// ErrTeardownTimeout as the Cause
+	Abandoned
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case PruneStarted:
+		return "PruneStarted"
+	case Pruned:
+		return "Pruned"
+	case Abandoned:
+		return "Abandoned"
+	default:
+		return "Unknown"
+	}
+}
+
+// Events returns a channel which receives the lifecycle events of this
+// tree, the channel is closed after the Pruned event.
+//
+// Events that happened before the call are replayed, so it is safe to call
+// Events at any moment. All calls return the same channel.
+func (t *tree) Events() <-chan Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.events == nil {
+		// a tree has at most two events, so they never block
+		t.events = make(chan Event, 2)
+		for _, e := range t.eventsLog {
+			t.events <- e
+		}
+		if t.finished() {
+			close(t.events)
+		}
+	}
+	return t.events
+}
+
+// emit records the event and sends it to the Events channel if
+// anyone asked for it
+func (t *tree) emit(e Event) {
+	e.Tags = t.Tags()
+	currentLogger().LogEvent(t.pid, t.Name(), e)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.eventsLog == nil {
+		// every tree ends up with both events
+		t.eventsLog = make([]Event, 0, 2)
+	}
+	t.eventsLog = append(t.eventsLog, e)
+	t.queueForListeners(e)
+	if t.events == nil {
+		return
+	}
+	t.events <- e
+	if e.Kind == Pruned {
+		close(t.events)
+	}
+}
+
+// finished returns true after the Pruned event, t.mu must be held
+func (t *tree) finished() bool {
+	n := len(t.eventsLog)
+	return n > 0 && t.eventsLog[n-1].Kind == Pruned
+}
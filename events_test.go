@@ -0,0 +1,54 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEventsPruneCause(t *testing.T) {
+	branch := Root().Branch()
+	events := branch.Events()
+	cause := errors.New("shutting down")
+	branch.PruneWith(cause)
+	<-branch.Done()
+
+	var got []Event
+	for e := range events {
+		got = append(got, e)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expecting 2 events got %v", got)
+	}
+	if got[0].Kind != PruneStarted || got[0].Cause != cause || got[0].PID != branch.PID() {
+		t.Fatalf("invalid prune started event %v", got[0])
+	}
+	if got[1].Kind != Pruned || got[1].Cause != cause {
+		t.Fatalf("invalid pruned event %v", got[1])
+	}
+	if branch.Cause() != cause {
+		t.Fatalf("expecting cause %v got %v", cause, branch.Cause())
+	}
+}
+
+func TestEventsReplay(t *testing.T) {
+	branch := Root().Branch()
+	branch.Prune()
+	<-branch.Done()
+
+	var kinds []EventKind
+	for e := range branch.Events() {
+		kinds = append(kinds, e.Kind)
+	}
+	if len(kinds) != 2 || kinds[0] != PruneStarted || kinds[1] != Pruned {
+		t.Fatalf("past events should be replayed, got %v", kinds)
+	}
+}
+
+func TestProcessErrorIsCause(t *testing.T) {
+	err := errors.New("failed")
+	branch := Root().BranchFunc(func(Tree) error { return err })
+	<-branch.Done()
+	if branch.Cause() != err {
+		t.Fatalf("expecting cause %v got %v", err, branch.Cause())
+	}
+}
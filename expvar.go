@@ -0,0 +1,109 @@
+package jungle
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// expvarMetrics counts branches for PublishExpvar and forwards every
+	// measurement to the Metrics that was configured before it
+	expvarMetrics struct {
+		// next holds a metricsHolder
+		next atomic.Value
+
+		total  atomic.Int64
+		active atomic.Int64
+		prunes atomic.Int64
+
+		mu         sync.Mutex
+		lastPrunes int64
+		lastRead   time.Time
+	}
+)
+
+var (
+	publishExpvar sync.Once
+	jungleExpvar  = &expvarMetrics{}
+)
+
+// PublishExpvar registers the "jungle" map under expvar, so the stats show
+// up in /debug/vars:
+//
+//   - branches_total: trees started since PublishExpvar was called
+//   - branches_active: trees started and not yet done
+//   - prunes_total: trees which received the prune signal
+//   - prune_rate: prunes per second since the previous read
+//
+// The counts are collected by installing a Metrics which forwards to the
+// current one, so it should be called after SetMetrics/Configure. Calling
+// SetMetrics afterwards stops the counts from changing until PublishExpvar
+// is called again.
+func PublishExpvar() {
+	m := jungleExpvar
+	publishExpvar.Do(func() {
+		m.lastRead = time.Now()
+		vars := new(expvar.Map).Init()
+		vars.Set("branches_total", expvar.Func(func() interface{} { return m.total.Load() }))
+		vars.Set("branches_active", expvar.Func(func() interface{} { return m.active.Load() }))
+		vars.Set("prunes_total", expvar.Func(func() interface{} { return m.prunes.Load() }))
+		vars.Set("prune_rate", expvar.Func(func() interface{} { return m.pruneRate() }))
+		expvar.Publish("jungle", vars)
+	})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current := currentMetrics(); current != Metrics(m) {
+		m.next.Store(metricsHolder{current})
+		SetMetrics(m)
+	}
+}
+
+// pruneRate returns the number of prunes per second since the last call
+func (m *expvarMetrics) pruneRate() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now, prunes := time.Now(), m.prunes.Load()
+	elapsed := now.Sub(m.lastRead).Seconds()
+	delta := prunes - m.lastPrunes
+	m.lastRead, m.lastPrunes = now, prunes
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(delta) / elapsed
+}
+
+// forward returns the Metrics which was configured before m
+func (m *expvarMetrics) forward() Metrics {
+	if h, ok := m.next.Load().(metricsHolder); ok {
+		return h.Metrics
+	}
+	return nopMetrics{}
+}
+
+func (m *expvarMetrics) BranchStartLatency(d time.Duration) {
+	m.forward().BranchStartLatency(d)
+}
+
+func (m *expvarMetrics) BranchStarted() {
+	m.total.Add(1)
+	m.active.Add(1)
+	if lm, ok := m.forward().(LifecycleMetrics); ok {
+		lm.BranchStarted()
+	}
+}
+
+func (m *expvarMetrics) BranchPruned() {
+	m.prunes.Add(1)
+	if lm, ok := m.forward().(LifecycleMetrics); ok {
+		lm.BranchPruned()
+	}
+}
+
+func (m *expvarMetrics) BranchDone() {
+	m.active.Add(-1)
+	if lm, ok := m.forward().(LifecycleMetrics); ok {
+		lm.BranchDone()
+	}
+}
@@ -0,0 +1,73 @@
+package jungle
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	PublishExpvar()
+	defer SetMetrics(nil)
+	PublishExpvar()
+
+	read := func() map[string]float64 {
+		v := expvar.Get("jungle")
+		if v == nil {
+			t.Fatal("jungle should be registered under expvar")
+		}
+		stats := map[string]float64{}
+		if err := json.Unmarshal([]byte(v.String()), &stats); err != nil {
+			t.Fatal(err)
+		}
+		return stats
+	}
+	before := read()
+
+	localRoot := Root().Branch()
+	block := make(chan Signal)
+	started := make(chan Signal, 10)
+	for i := 0; i < 10; i++ {
+		localRoot.BranchFunc(func(Tree) error {
+			started <- Signal{}
+			<-block
+			return nil
+		})
+	}
+	for i := 0; i < 10; i++ {
+		<-started
+	}
+	during := read()
+	if got := during["branches_total"] - before["branches_total"]; got < 11 {
+		t.Fatalf("should count at least 11 new branches got %v", got)
+	}
+	if during["branches_active"] < 11 {
+		t.Fatalf("should have at least 11 active branches got %v", during["branches_active"])
+	}
+
+	close(block)
+	localRoot.Prune()
+	<-localRoot.Done()
+	after := read()
+	if got := after["prunes_total"] - during["prunes_total"]; got < 11 {
+		t.Fatalf("should count at least 11 prunes got %v", got)
+	}
+	if after["prune_rate"] <= 0 {
+		t.Fatalf("prune rate should be positive got %v", after["prune_rate"])
+	}
+}
+
+func TestPublishExpvarLongLivedTree(t *testing.T) {
+	SetMetrics(nil)
+	defer SetMetrics(nil)
+	// started before the counters are installed
+	early := Root().Branch()
+	PublishExpvar()
+
+	before := jungleExpvar.active.Load()
+	early.Prune()
+	<-early.Done()
+	if after := jungleExpvar.active.Load(); after < before {
+		t.Fatalf("trees started before PublishExpvar should not be counted, active went from %v to %v", before, after)
+	}
+}
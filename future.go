@@ -0,0 +1,49 @@
+package jungle
+
+type (
+	// Future holds the result of a branch created by BranchResult
+	Future[T any] struct {
+		tree Tree
+
+		// written by the process function before the branch is done
+		settled bool
+		value   T
+		err     error
+	}
+)
+
+// BranchResult creates a new branch of parent which runs fn, just like
+// BranchFunc, but the value returned by fn is kept in the returned Future.
+func BranchResult[T any](parent Tree, fn func(Tree) (T, error)) *Future[T] {
+	f := &Future[T]{}
+	f.tree = parent.BranchFunc(f.process(fn))
+	return f
+}
+
+// process returns a process function which settles f
+func (f *Future[T]) process(fn func(Tree) (T, error)) func(Tree) error {
+	return func(t Tree) error {
+		f.value, f.err = fn(t)
+		f.settled = true
+		return f.err
+	}
+}
+
+// Tree returns the branch running the function
+func (f *Future[T]) Tree() Tree {
+	return f.tree
+}
+
+// Get waits until the branch is done and returns the value and error
+// returned by the function.
+//
+// If the function never ran (eg.: the parent was paused), the zero value
+// is returned along with the Cause of the branch.
+func (f *Future[T]) Get() (T, error) {
+	<-f.tree.Done()
+	if !f.settled {
+		var zero T
+		return zero, f.tree.Cause()
+	}
+	return f.value, f.err
+}
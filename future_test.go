@@ -0,0 +1,38 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBranchResult(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	f := BranchResult(localRoot, func(Tree) (int, error) {
+		return 42, nil
+	})
+	if v, err := f.Get(); err != nil || v != 42 {
+		t.Fatalf("expecting 42 got %v, %v", v, err)
+	}
+	// Get can be called many times
+	if v, _ := f.Get(); v != 42 {
+		t.Fatalf("expecting 42 got %v", v)
+	}
+
+	failure := errors.New("failed")
+	fs := BranchResult(localRoot, func(Tree) (string, error) {
+		return "partial", failure
+	})
+	if v, err := fs.Get(); err != failure || v != "partial" {
+		t.Fatalf("expecting partial and %v got %v, %v", failure, v, err)
+	}
+
+	localRoot.Pause()
+	fp := BranchResult(localRoot, func(Tree) (int, error) {
+		return 1, nil
+	})
+	if v, err := fp.Get(); err != ErrPaused || v != 0 {
+		t.Fatalf("expecting 0 and ErrPaused got %v, %v", v, err)
+	}
+}
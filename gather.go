@@ -0,0 +1,177 @@
+package jungle
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type (
+	// Partial is one slot of the results of GatherPartial, Done is false
+	// for tasks which didn't complete successfully
+	Partial[T any] struct {
+		Value T
+		Done  bool
+	}
+
+	// GatherHandle controls the tasks started by StartGather
+	GatherHandle[T any] struct {
+		scope    Tree
+		futures  []*Future[T]
+		canceled []atomic.Bool
+	}
+
+	// GatherResult is one slot of the results of GatherHandle.Wait
+	GatherResult[T any] struct {
+		Value T
+		Err   error
+	}
+)
+
+// Gather runs fn once for each input, concurrently, each one on its own
+// branch of parent and collects the results in the same order as inputs.
+//
+// The first error prunes all the remaining tasks and is returned without
+// any results. If parent is pruned before all tasks complete successfully,
+// context.Canceled is returned.
+func Gather[T, I any](parent Tree, inputs []I, fn func(Tree, I) (T, error)) ([]T, error) {
+	return GatherN(parent, inputs, len(inputs), fn)
+}
+
+// GatherN works like Gather but at most concurrency tasks run at the same
+// time, new tasks start as soon as running ones complete. Results are still
+// returned in the same order as inputs.
+//
+// Tasks are executed by a Runner, so tasks which didn't start before an
+// error are never called.
+func GatherN[T, I any](parent Tree, inputs []I, concurrency int, fn func(Tree, I) (T, error)) ([]T, error) {
+	scope, futures := startGather(parent, inputs, concurrency, fn, nil)
+	defer func() {
+		scope.Prune()
+		<-scope.Done()
+	}()
+	results := make([]T, len(inputs))
+	for i, f := range futures {
+		v, err := f.Get()
+		if err != nil || !f.settled {
+			return nil, gatherErr(scope)
+		}
+		results[i] = v
+	}
+	return results, nil
+}
+
+// GatherPartial works like Gather but when it fails, eg.: parent was
+// pruned during a long batch, the results of the tasks which completed
+// are returned along with the error instead of being discarded.
+//
+// The results are in the same order as inputs, the slots of tasks which
+// failed, were interrupted or never ran have Done set to false. If all
+// tasks complete the error is nil and every slot is Done.
+//
+// Unlike Gather, it only returns after all tasks are done.
+func GatherPartial[T, I any](parent Tree, inputs []I, fn func(Tree, I) (T, error)) ([]Partial[T], error) {
+	scope, futures := startGather(parent, inputs, len(inputs), fn, nil)
+	defer func() {
+		scope.Prune()
+		<-scope.Done()
+	}()
+	results := make([]Partial[T], len(inputs))
+	var failed bool
+	for i, f := range futures {
+		v, err := f.Get()
+		if err != nil || !f.settled {
+			failed = true
+			continue
+		}
+		results[i] = Partial[T]{Value: v, Done: true}
+	}
+	if failed {
+		return results, gatherErr(scope)
+	}
+	return results, nil
+}
+
+// StartGather works like Gather but returns right away with a handle, which
+// can cancel individual tasks while the others keep running, eg.: when
+// some inputs become irrelevant. Use Wait to collect the results.
+func StartGather[T, I any](parent Tree, inputs []I, fn func(Tree, I) (T, error)) *GatherHandle[T] {
+	h := &GatherHandle[T]{canceled: make([]atomic.Bool, len(inputs))}
+	h.scope, h.futures = startGather(parent, inputs, len(inputs), fn, func(i int) bool {
+		return h.canceled[i].Load()
+	})
+	return h
+}
+
+// Cancel prunes the task of the i-th input, its slot reports
+// context.Canceled and whatever it returns is ignored, so sibling tasks
+// are not disturbed.
+func (h *GatherHandle[T]) Cancel(i int) {
+	h.canceled[i].Store(true)
+	h.futures[i].Tree().Prune()
+}
+
+// Wait blocks until all tasks are done and returns their results in the
+// same order as inputs.
+//
+// Like Gather, the first error prunes the remaining tasks and is returned,
+// or context.Canceled if parent was pruned. Cancelled tasks are not
+// errors, if all other tasks succeed the error is nil.
+func (h *GatherHandle[T]) Wait() ([]GatherResult[T], error) {
+	defer func() {
+		h.scope.Prune()
+		<-h.scope.Done()
+	}()
+	results := make([]GatherResult[T], len(h.futures))
+	var failed bool
+	for i, f := range h.futures {
+		v, err := f.Get()
+		switch {
+		case h.canceled[i].Load():
+			results[i].Err = context.Canceled
+		case err != nil || !f.settled:
+			failed = true
+			results[i].Err = err
+			if err == nil {
+				results[i].Err = context.Canceled
+			}
+		default:
+			results[i].Value = v
+		}
+	}
+	if failed {
+		return results, gatherErr(h.scope)
+	}
+	return results, nil
+}
+
+// startGather branches scope from parent and starts one task per input on
+// it, the first error prunes scope unless the task was canceled
+func startGather[T, I any](parent Tree, inputs []I, concurrency int, fn func(Tree, I) (T, error), canceled func(int) bool) (Tree, []*Future[T]) {
+	runner := NewRunner(concurrency)
+	scope := parent.Branch()
+	futures := make([]*Future[T], len(inputs))
+	for i, in := range inputs {
+		i, in := i, in
+		f := &Future[T]{}
+		f.tree = scope.RunOn(runner, f.process(func(t Tree) (T, error) {
+			v, err := fn(t, in)
+			if err != nil && (canceled == nil || !canceled(i)) {
+				scope.PruneWith(err)
+			}
+			return v, err
+		}))
+		futures[i] = f
+	}
+	return scope, futures
+}
+
+// gatherErr returns the first error of a task or context.Canceled if scope
+// was pruned for other reasons
+func gatherErr(scope Tree) error {
+	// wait for the prune to be captured so Cause is the first error
+	<-scope.Pruned()
+	if cause := scope.Cause(); cause != nil {
+		return cause
+	}
+	return context.Canceled
+}
@@ -0,0 +1,158 @@
+package jungle
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGather(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	inputs := []int{5, 1, 4, 2, 3}
+	results, err := Gather(localRoot, inputs, func(_ Tree, in int) (int, error) {
+		// finish out of order
+		time.Sleep(time.Duration(in) * time.Millisecond)
+		return in * 10, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, in := range inputs {
+		if results[i] != in*10 {
+			t.Fatalf("results should be in input order, got %v", results)
+		}
+	}
+}
+
+func TestGatherError(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	failure := errors.New("failed")
+	var started, finished int32
+	results, err := Gather(localRoot, []int{0, 1, 2, 3}, func(b Tree, in int) (int, error) {
+		if in == 2 {
+			return 0, failure
+		}
+		atomic.AddInt32(&started, 1)
+		<-b.Pruned()
+		atomic.AddInt32(&finished, 1)
+		return in, nil
+	})
+	if err != failure || results != nil {
+		t.Fatalf("expecting %v got %v, %v", failure, results, err)
+	}
+	if s, f := atomic.LoadInt32(&started), atomic.LoadInt32(&finished); s != f {
+		t.Fatalf("all the remaining tasks should be pruned, %v started but %v finished", s, f)
+	}
+}
+
+func TestGatherParentPruned(t *testing.T) {
+	localRoot := Root().Branch()
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		localRoot.Prune()
+	}()
+	_, err := Gather(localRoot, []int{0, 1}, func(b Tree, in int) (int, error) {
+		<-b.Pruned()
+		return in, errors.New("interrupted")
+	})
+	if err != context.Canceled {
+		t.Fatalf("expecting context.Canceled got %v", err)
+	}
+}
+
+func TestGatherN(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	var running, peak int32
+	inputs := make([]int, 50)
+	for i := range inputs {
+		inputs[i] = i
+	}
+	results, err := GatherN(localRoot, inputs, 3, func(_ Tree, in int) (int, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return in * 2, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range inputs {
+		if results[i] != i*2 {
+			t.Fatalf("results should be in input order, got %v", results)
+		}
+	}
+	if p := atomic.LoadInt32(&peak); p > 3 || p == 0 {
+		t.Fatalf("at most 3 tasks should run at once, got %v", p)
+	}
+}
+
+func TestGatherPartial(t *testing.T) {
+	localRoot := Root().Branch()
+	finished := make(chan Signal, 2)
+	go func() {
+		<-finished
+		<-finished
+		localRoot.Prune()
+	}()
+	results, err := GatherPartial(localRoot, []int{0, 1, 2, 3}, func(b Tree, in int) (int, error) {
+		if in%2 == 1 {
+			defer func() { finished <- Signal{} }()
+			return in * 10, nil
+		}
+		<-b.Pruned()
+		return 0, errors.New("interrupted")
+	})
+	if err != context.Canceled {
+		t.Fatalf("expecting context.Canceled got %v", err)
+	}
+	expected := []Partial[int]{{}, {Value: 10, Done: true}, {}, {Value: 30, Done: true}}
+	if len(results) != len(expected) {
+		t.Fatalf("expecting %v got %v", expected, results)
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Fatalf("expecting %v got %v", expected, results)
+		}
+	}
+}
+
+func TestStartGatherCancel(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	release := make(chan Signal)
+	h := StartGather(localRoot, []int{0, 1, 2}, func(b Tree, in int) (int, error) {
+		if in == 1 {
+			<-b.Pruned()
+			return 0, errors.New("interrupted")
+		}
+		<-release
+		return in * 10, nil
+	})
+	h.Cancel(1)
+	close(release)
+	results, err := h.Wait()
+	if err != nil {
+		t.Fatalf("cancelling a task should not fail the others, got %v", err)
+	}
+	expected := []GatherResult[int]{{Value: 0}, {Err: context.Canceled}, {Value: 20}}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Fatalf("expecting %v got %v", expected, results)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package jungle
+
+// GoroutineStats returns how many goroutines jungle is running for t and
+// its descendants: one lifecycle per tree plus one for each process function
+// running on its own goroutine (process functions executed by a Runner are
+// not included).
+//
+// Goroutines started by the process functions themselves are not counted,
+// comparing this to runtime.NumGoroutine tells which side is growing. For a
+// root it is a single atomic load, for other trees the subtree is traversed.
+func GoroutineStats(t Tree) int {
+	tt, ok := t.(*tree)
+	if !ok {
+		return 0
+	}
+	if tt.IsRoot() {
+		return int(tt.rootGoroutines.Load())
+	}
+	return tt.subtreeGoroutines()
+}
+
+// countGoroutines tracks goroutines started (delta > 0) or finished
+// (delta < 0) on behalf of t
+func (t *tree) countGoroutines(delta int32) {
+	t.goroutines.Add(delta)
+	t.root.rootGoroutines.Add(int64(delta))
+}
+
+// subtreeGoroutines sums the goroutines of t and all of its remaining
+// branches, including the ones being pruned
+func (t *tree) subtreeGoroutines() int {
+	n := int(t.goroutines.Load())
+	for _, c := range t.remaining() {
+		n += c.subtreeGoroutines()
+	}
+	return n
+}
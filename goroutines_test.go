@@ -0,0 +1,39 @@
+package jungle
+
+import "testing"
+
+func TestGoroutineStats(t *testing.T) {
+	root := New()
+	if n := GoroutineStats(root); n != 1 {
+		t.Fatalf("a new root should have only its lifecycle, got %v", n)
+	}
+	parent := root.Branch()
+	started := make(chan Signal, 3)
+	for i := 0; i < 3; i++ {
+		parent.BranchFunc(func(t Tree) error {
+			started <- Signal{}
+			<-t.Pruned()
+			return nil
+		})
+	}
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+	// 1 lifecycle for parent, plus 3 lifecycles and 3 processes
+	if n := GoroutineStats(parent); n != 7 {
+		t.Fatalf("expecting 7 goroutines for the subtree got %v", n)
+	}
+	if n := GoroutineStats(root); n != 8 {
+		t.Fatalf("expecting 8 goroutines for the root got %v", n)
+	}
+	parent.Prune()
+	<-parent.Done()
+	if n := GoroutineStats(root); n != 1 {
+		t.Fatalf("goroutines should be gone after prune, got %v", n)
+	}
+	root.Prune()
+	<-root.Done()
+	if n := GoroutineStats(root); n != 0 {
+		t.Fatalf("expecting no goroutines after the root is done, got %v", n)
+	}
+}
@@ -0,0 +1,71 @@
+package jungle
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type (
+	// HandlerOptions controls what the debug handler is allowed to do
+	HandlerOptions struct {
+		// AllowPrune enables the prune action
+		AllowPrune bool
+	}
+
+	handler struct {
+		tree Tree
+		opts HandlerOptions
+	}
+)
+
+// Handler returns an http.Handler to inspect t while it is running.
+//
+// A GET request returns the JSON snapshot of t, or of one of its
+// descendants when the pid query parameter is present.
+//
+// A POST request with ?pid=N&action=prune prunes the given branch, as long
+// as AllowPrune is set.
+func Handler(t Tree, opts HandlerOptions) http.Handler {
+	return &handler{tree: t, opts: opts}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	target := h.tree
+	if p := req.URL.Query().Get("pid"); p != "" {
+		pid, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid pid", http.StatusBadRequest)
+			return
+		}
+		var found bool
+		target, found = h.tree.FindByPID(pid)
+		if !found {
+			http.Error(w, "branch not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	switch action := req.URL.Query().Get("action"); action {
+	case "":
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Snapshot(target))
+	case "prune":
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !h.opts.AllowPrune {
+			http.Error(w, "prune is not allowed", http.StatusForbidden)
+			return
+		}
+		target.Prune()
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "unknown action "+strconv.Quote(action), http.StatusBadRequest)
+	}
+}
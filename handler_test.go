@@ -0,0 +1,84 @@
+package jungle
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandlerSnapshot(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	child := localRoot.Branch()
+	child.SetName("child")
+
+	srv := httptest.NewServer(Handler(localRoot, HandlerOptions{}))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var snap TreeSnapshot
+	if err := json.NewDecoder(res.Body).Decode(&snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.PID != localRoot.PID() || len(snap.Children) != 1 || snap.Children[0].Name != "child" {
+		t.Fatalf("invalid snapshot %#v", snap)
+	}
+
+	res, err = http.Get(fmt.Sprintf("%v?pid=%v", srv.URL, child.PID()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if err := json.NewDecoder(res.Body).Decode(&snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.PID != child.PID() {
+		t.Fatalf("expecting snapshot of %v got %v", child.PID(), snap.PID)
+	}
+}
+
+func TestHandlerPrune(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	child := localRoot.Branch()
+
+	pruneURL := func(base string) string {
+		return fmt.Sprintf("%v?pid=%v&action=prune", base, child.PID())
+	}
+	forbidden := httptest.NewServer(Handler(localRoot, HandlerOptions{}))
+	defer forbidden.Close()
+	res, err := http.Post(pruneURL(forbidden.URL), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("prune should be forbidden, got %v", res.Status)
+	}
+
+	allowed := httptest.NewServer(Handler(localRoot, HandlerOptions{AllowPrune: true}))
+	defer allowed.Close()
+	res, err = http.Post(pruneURL(allowed.URL), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		t.Fatalf("prune should be accepted, got %v", res.Status)
+	}
+	select {
+	case <-child.Done():
+	case <-time.After(time.Second):
+		t.Fatal("child should be pruned")
+	}
+	if isClosed(localRoot.Pruned()) {
+		t.Fatal("only the child should be pruned")
+	}
+}
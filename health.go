@@ -0,0 +1,127 @@
+package jungle
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// HealthChecker reports if a tree is ready to do its work, eg.: by
+	// pinging a database pool. It must return quickly, as it is polled.
+	HealthChecker func() bool
+)
+
+const (
+	defaultHealthPollInterval = 10 * time.Millisecond
+)
+
+var (
+	healthPollInterval atomic.Int64
+)
+
+func init() {
+	SetHealthPollInterval(defaultHealthPollInterval)
+}
+
+// SetHealthPollInterval changes how often WaitHealthy calls the health
+// checkers. The default is 10 milliseconds, zero or less restores it.
+func SetHealthPollInterval(d time.Duration) {
+	if d <= 0 {
+		d = defaultHealthPollInterval
+	}
+	healthPollInterval.Store(int64(d))
+}
+
+// MarkHealthy reports that t finished its setup and is ready to do its
+// work, eg.: a server which is already listening. Calling it more than
+// once has no effect, nor does calling it on trees which were not created
+// by jungle.
+func MarkHealthy(t Tree) {
+	tt, ok := t.(*tree)
+	if !ok {
+		return
+	}
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.isHealthy {
+		return
+	}
+	tt.isHealthy = true
+	if tt.healthy != nil {
+		close(tt.healthy)
+	}
+}
+
+// Healthy returns a channel which is closed once MarkHealthy is called on
+// t, a tree that is done without calling it never becomes healthy. Trees
+// not created by this package can't be observed, nil is returned for them.
+func Healthy(t Tree) <-chan Signal {
+	tt, ok := t.(*tree)
+	if !ok {
+		return nil
+	}
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.healthy == nil {
+		tt.healthy = make(chan Signal)
+		if tt.isHealthy {
+			close(tt.healthy)
+		}
+	}
+	return tt.healthy
+}
+
+// SetHealthChecker registers check as the health checker of t, used by
+// WaitHealthy for trees which can't call MarkHealthy themselves. A nil
+// check removes it.
+func SetHealthChecker(t Tree, check HealthChecker) {
+	tt, ok := t.(*tree)
+	if !ok {
+		return
+	}
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.healthCheck = check
+}
+
+// WaitHealthy blocks until t is healthy and returns true, or returns false
+// if d elapses or t is pruned first.
+//
+// t is healthy once MarkHealthy is called or its health checker, see
+// SetHealthChecker, returns true. The checker is polled right away and
+// then at the interval set by SetHealthPollInterval. Once the checker
+// succeeds MarkHealthy is called, so Healthy and Swap see it as well.
+//
+// It is meant for startup orchestration, eg.: wait until the database
+// pool is ready before serving.
+func WaitHealthy(t Tree, d time.Duration) bool {
+	timeout := time.NewTimer(d)
+	defer timeout.Stop()
+	poll := time.NewTicker(time.Duration(healthPollInterval.Load()))
+	defer poll.Stop()
+	for {
+		if check := healthChecker(t); check != nil && check() {
+			MarkHealthy(t)
+			return true
+		}
+		select {
+		case <-Healthy(t):
+			return true
+		case <-t.Pruned():
+			return false
+		case <-timeout.C:
+			return false
+		case <-poll.C:
+		}
+	}
+}
+
+func healthChecker(t Tree) HealthChecker {
+	tt, ok := t.(*tree)
+	if !ok {
+		return nil
+	}
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	return tt.healthCheck
+}
@@ -0,0 +1,58 @@
+package jungle
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitHealthy(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	db := localRoot.Branch()
+	var ready atomic.Bool
+	SetHealthChecker(db, ready.Load)
+	time.AfterFunc(30*time.Millisecond, func() { ready.Store(true) })
+	if WaitHealthy(db, 10*time.Millisecond) {
+		t.Fatal("should time out before the checker flips")
+	}
+	if !WaitHealthy(db, time.Second) {
+		t.Fatal("should be healthy once the checker flips")
+	}
+	select {
+	case <-Healthy(db):
+	default:
+		t.Fatal("a successful check should mark the tree healthy")
+	}
+
+	marked := localRoot.Branch()
+	time.AfterFunc(10*time.Millisecond, func() { MarkHealthy(marked) })
+	if !WaitHealthy(marked, time.Second) {
+		t.Fatal("MarkHealthy should be enough without a checker")
+	}
+
+	pruned := localRoot.Branch()
+	SetHealthChecker(pruned, func() bool { return false })
+	time.AfterFunc(10*time.Millisecond, pruned.Prune)
+	start := time.Now()
+	if WaitHealthy(pruned, time.Second) || time.Since(start) > 500*time.Millisecond {
+		t.Fatal("should give up as soon as the tree is pruned")
+	}
+}
+
+func TestSetHealthPollInterval(t *testing.T) {
+	defer SetHealthPollInterval(0)
+	for _, d := range []time.Duration{0, -time.Second} {
+		SetHealthPollInterval(d)
+		branch := Root().Branch()
+		SetHealthChecker(branch, func() bool { return false })
+		if WaitHealthy(branch, time.Millisecond) {
+			t.Fatal("should not be healthy")
+		}
+		branch.Prune()
+	}
+	if d := time.Duration(healthPollInterval.Load()); d != defaultHealthPollInterval {
+		t.Fatalf("expecting the default interval got %v", d)
+	}
+}
@@ -0,0 +1,67 @@
+// Package httpjungle runs HTTP servers as branches of a jungle tree,
+// shutting them down gracefully when the tree is pruned.
+package httpjungle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/andrebq/jungle"
+)
+
+var (
+	gracePeriod atomic.Int64
+)
+
+func init() {
+	SetGracePeriod(5 * time.Second)
+}
+
+// SetGracePeriod changes how long in-flight requests have to complete
+// after the tree is pruned, when the tree has no deadline of its own.
+// The default is 5 seconds.
+func SetGracePeriod(d time.Duration) {
+	gracePeriod.Store(int64(d))
+}
+
+// Serve creates a branch of t which serves HTTP requests from l using srv.
+// Once the branch is pruned, srv.Shutdown is called so in-flight requests
+// can complete.
+//
+// Requests have until the deadline of the branch to complete, or the grace
+// period if there is no deadline ahead. The error from the server, if any,
+// is available from Err once the branch is done.
+func Serve(t jungle.Tree, srv *http.Server, l net.Listener) jungle.Tree {
+	return t.BranchFunc(func(branch jungle.Tree) error {
+		served := make(chan error, 1)
+		go func() {
+			served <- srv.Serve(l)
+		}()
+		select {
+		case err := <-served:
+			return err
+		case <-branch.Pruned():
+		}
+		ctx, cancel := graceContext(branch)
+		defer cancel()
+		err := srv.Shutdown(ctx)
+		if serveErr := <-served; err == nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			err = serveErr
+		}
+		return err
+	})
+}
+
+// graceContext returns the context used to shutdown the server
+func graceContext(t jungle.Tree) (context.Context, context.CancelFunc) {
+	if d, ok := jungle.AsDeadlined(t); ok {
+		if deadline, _ := d.Deadline(); time.Until(deadline) > 0 {
+			return context.WithDeadline(context.Background(), deadline)
+		}
+	}
+	return context.WithTimeout(context.Background(), time.Duration(gracePeriod.Load()))
+}
@@ -0,0 +1,77 @@
+package httpjungle
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/andrebq/jungle"
+)
+
+func TestServe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inFlight := make(chan struct{})
+	release := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(inFlight)
+		<-release
+		io.WriteString(w, "done")
+	})}
+	branch := Serve(jungle.Root(), srv, l)
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		res, err := http.Get("http://" + l.Addr().String())
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		response <- result{string(body), err}
+	}()
+
+	<-inFlight
+	branch.Prune()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	select {
+	case r := <-response:
+		if r.err != nil || r.body != "done" {
+			t.Fatalf("the in-flight request should complete, got %q %v", r.body, r.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request should complete")
+	}
+	select {
+	case <-branch.Done():
+	case <-time.After(time.Second):
+		t.Fatal("branch should be done after shutdown")
+	}
+	if branch.Err() != nil {
+		t.Fatalf("a graceful shutdown should not report errors, got %v", branch.Err())
+	}
+}
+
+func TestServeError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	branch := Serve(jungle.Root(), &http.Server{}, l)
+	<-branch.Done()
+	if branch.Err() == nil {
+		t.Fatal("the error from Serve should be reported")
+	}
+}
@@ -0,0 +1,52 @@
+package jungle
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+)
+
+const (
+	// LabelPID is the pprof label holding the PID of the branch running
+	// a goroutine, see WithGoroutineLabels
+	LabelPID = "jungle.pid"
+	// LabelName is the pprof label holding the name of the branch
+	// running a goroutine, it is omitted for branches without a name
+	LabelName = "jungle.name"
+)
+
+// WithGoroutineLabels sets pprof labels with the PID and name of the
+// branch on the goroutine running its process function, so goroutine
+// profiles and dumps (debug=1) show which branch each goroutine belongs
+// to. Goroutines started by the process function inherit the labels.
+//
+// The name is taken when the process starts, later calls to SetName are
+// not reflected. It is disabled by default since it allocates a context
+// for every process.
+func WithGoroutineLabels() RootOption {
+	return func(c *rootConfig) {
+		c.goroutineLabels = true
+	}
+}
+
+// labels returns ctx with the pprof labels of t, if they are enabled
+func (t *tree) labels(ctx context.Context) context.Context {
+	if !t.config.goroutineLabels {
+		return ctx
+	}
+	labels := []string{LabelPID, strconv.FormatUint(t.pid, 10)}
+	if name := t.Name(); name != "" {
+		labels = append(labels, LabelName, name)
+	}
+	return pprof.WithLabels(ctx, pprof.Labels(labels...))
+}
+
+// labelGoroutine sets the labels of t on the calling goroutine, the
+// returned function clears them, as runners reuse their goroutines
+func (t *tree) labelGoroutine() func() {
+	if !t.config.goroutineLabels {
+		return func() {}
+	}
+	pprof.SetGoroutineLabels(t.labels(context.Background()))
+	return func() { pprof.SetGoroutineLabels(context.Background()) }
+}
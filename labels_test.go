@@ -0,0 +1,49 @@
+package jungle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+func TestWithGoroutineLabels(t *testing.T) {
+	root := New(WithGoroutineLabels())
+	defer root.Prune()
+
+	type labels struct{ pid, name string }
+	found := make(chan labels, 1)
+	release := make(chan Signal)
+	branch := root.BranchFuncCtx(func(ctx context.Context, b Tree) error {
+		pid, _ := pprof.Label(ctx, LabelPID)
+		name, _ := pprof.Label(ctx, LabelName)
+		found <- labels{pid, name}
+		<-release
+		return nil
+	})
+	got := <-found
+	if got.pid != fmt.Sprint(branch.PID()) || got.name != "" {
+		t.Fatalf("expecting pid %v without name, got %+v", branch.PID(), got)
+	}
+
+	var profile bytes.Buffer
+	pprof.Lookup("goroutine").WriteTo(&profile, 1)
+	close(release)
+	label := fmt.Sprintf("%q:%q", LabelPID, fmt.Sprint(branch.PID()))
+	if !strings.Contains(profile.String(), label) {
+		t.Fatalf("goroutine profile should contain %v", label)
+	}
+
+	plain := New()
+	defer plain.Prune()
+	var labeled bool
+	<-plain.BranchFuncCtx(func(ctx context.Context, b Tree) error {
+		_, labeled = pprof.Label(ctx, LabelPID)
+		return nil
+	}).Done()
+	if labeled {
+		t.Fatal("labels should be disabled by default")
+	}
+}
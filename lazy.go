@@ -0,0 +1,50 @@
+package jungle
+
+import "sync"
+
+type (
+	// LazyTree is a Tree whose process function only starts on demand
+	LazyTree interface {
+		Tree
+
+		// Ensure starts the process function, if it didn't start yet
+		Ensure()
+	}
+
+	// lazyStart holds the process function of a lazy branch until it is
+	// handed to the lifecycle by Ensure
+	lazyStart struct {
+		once    sync.Once
+		fn      processFunc
+		process chan processFunc
+	}
+)
+
+// LazyBranch creates a new branch of parent running fn, but fn only starts
+// once Ensure is called or a branch is created under it. Until then it
+// behaves like an empty branch, which can be pruned as usual without ever
+// calling fn, and no goroutine is started for fn.
+//
+// If parent was not created by this package the returned branch is
+// already done with ErrForeignTree and fn is never called.
+func LazyBranch(parent Tree, fn func(Tree) error) LazyTree {
+	p, ok := parent.(*tree)
+	if !ok {
+		return rejectedTree(ErrForeignTree)
+	}
+	branch := newTree(p, nil)
+	branch.lazy = &lazyStart{fn: fn, process: make(chan processFunc, 1)}
+	p.grow(branch)
+	return branch
+}
+
+// Ensure starts the process function of a branch created by LazyBranch,
+// it has no effect on other trees or if it was already started.
+func (t *tree) Ensure() {
+	if t.lazy == nil {
+		return
+	}
+	t.lazy.once.Do(func() {
+		t.lazy.process <- t.lazy.fn
+	})
+}
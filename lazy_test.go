@@ -0,0 +1,68 @@
+package jungle
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLazyBranch(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	started := make(chan Signal)
+	var runs atomic.Int32
+	fn := func(t Tree) error {
+		if runs.Add(1) == 1 {
+			close(started)
+		}
+		<-t.Pruned()
+		return nil
+	}
+	lazy := LazyBranch(parent, fn)
+	time.Sleep(10 * time.Millisecond)
+	if runs.Load() != 0 || lazy.IsPruned() {
+		t.Fatal("fn should not run before Ensure")
+	}
+	if n := GoroutineStats(lazy); n != 1 {
+		t.Fatalf("only the lifecycle should run before Ensure, got %v goroutines", n)
+	}
+	lazy.Ensure()
+	lazy.Ensure()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("fn should run after Ensure")
+	}
+
+	byChild := LazyBranch(parent, fn)
+	byChild.Branch()
+	deadline := time.Now().Add(time.Second)
+	for runs.Load() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("branching under a lazy branch should start it")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	never := LazyBranch(parent, func(Tree) error {
+		t.Error("a pruned lazy branch should never run")
+		return nil
+	})
+	never.Prune()
+	<-never.Done()
+}
+
+func TestLazyBranchForeignParent(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	lazy := LazyBranch(foreignTree{parent}, func(Tree) error {
+		t.Error("fn should not run under a foreign parent")
+		return nil
+	})
+	lazy.Ensure()
+	<-lazy.Done()
+	if !errors.Is(lazy.Cause(), ErrForeignTree) {
+		t.Fatalf("expecting ErrForeignTree got %v", lazy.Cause())
+	}
+}
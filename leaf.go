@@ -0,0 +1,36 @@
+package jungle
+
+// Leaf returns a channel which is closed the next time t goes from having
+// branches to having none, eg.: to know when all the work of a node
+// completed. Each transition closes the channels returned before it, later
+// calls return a new channel that waits for the next transition.
+//
+// The channel is also closed once t is done. Trees not created by this
+// package can't be observed, nil is returned for them.
+func Leaf(t Tree) <-chan struct{} {
+	tt, ok := t.(*tree)
+	if !ok {
+		return nil
+	}
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.leaf == nil {
+		tt.leaf = make(chan struct{})
+		select {
+		case <-tt.done:
+			close(tt.leaf)
+		default:
+		}
+	}
+	return tt.leaf
+}
+
+// becameLeaf closes the channel returned by Leaf, called by the lifecycle
+func (t *tree) becameLeaf() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.leaf != nil {
+		close(t.leaf)
+		t.leaf = nil
+	}
+}
@@ -0,0 +1,46 @@
+package jungle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaf(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	for round := 0; round < 2; round++ {
+		var children []Tree
+		for i := 0; i < 3; i++ {
+			children = append(children, parent.Branch())
+		}
+		leaf := Leaf(parent)
+		for i, c := range children {
+			select {
+			case <-leaf:
+				t.Fatalf("round %v: Leaf fired with %v children left", round, len(children)-i)
+			default:
+			}
+			c.Prune()
+			<-c.Done()
+		}
+		select {
+		case <-leaf:
+		case <-time.After(time.Second):
+			t.Fatalf("round %v: Leaf should fire once all children are done", round)
+		}
+	}
+
+	parent.Prune()
+	<-parent.Done()
+	select {
+	case <-Leaf(parent):
+	default:
+		t.Fatal("Leaf should be closed once the tree is done")
+	}
+}
+
+func TestLeafForeignTree(t *testing.T) {
+	if Leaf(foreignTree{Root()}) != nil {
+		t.Fatal("trees from other implementations can't be observed")
+	}
+}
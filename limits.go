@@ -0,0 +1,61 @@
+package jungle
+
+import "errors"
+
+var (
+	// ErrMaxBranches is the cause of branches created while their root
+	// already had the maximum number of live branches
+	ErrMaxBranches = errors.New("jungle: too many branches")
+)
+
+// SetMaxBranches limits how many branches can be alive at the same time
+// anywhere under the root of this tree, new branches above the limit are
+// returned already done with ErrMaxBranches as their Cause (or the error
+// from TryBranch). Zero or less removes the limit.
+//
+// Branches already alive are not affected by a lower limit.
+func (t *tree) SetMaxBranches(n int) {
+	if n < 0 {
+		n = 0
+	}
+	t.root.maxBranches.Store(int64(n))
+}
+
+// TryBranch works like Branch but if the branch can't be created, eg.: the
+// tree is paused or at its limit, the cause is returned instead.
+func (t *tree) TryBranch() (Tree, error) {
+	return t.TryBranchFunc(nil)
+}
+
+// TryBranchFunc works like BranchFunc but if the branch can't be created,
+// eg.: the tree is paused or at its limit, the cause is returned instead
+// and fn is never called.
+func (t *tree) TryBranchFunc(fn func(Tree) error) (Tree, error) {
+	branch := t.grow(newTree(t, fn)).(*tree)
+	if branch.rejected {
+		return nil, branch.cause
+	}
+	return branch, nil
+}
+
+// reserve counts a new branch against the limit of the root, returning
+// false if the limit was reached
+func (t *tree) reserve() bool {
+	root := t.root
+	for {
+		n, max := root.live.Load(), root.maxBranches.Load()
+		if max > 0 && n >= max {
+			return false
+		}
+		if root.live.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// release removes t from the live count of its root, once it is done
+func (t *tree) release() {
+	if t.counted {
+		t.root.live.Add(-1)
+	}
+}
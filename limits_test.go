@@ -0,0 +1,40 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetMaxBranches(t *testing.T) {
+	root := New()
+	defer root.Prune()
+	root.SetMaxBranches(3)
+
+	parent, err := root.TryBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the limit applies to all descendants, not only direct children
+	child, err := parent.TryBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := child.TryBranch(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parent.TryBranchFunc(func(Tree) error {
+		t.Error("rejected branches should never run")
+		return nil
+	}); !errors.Is(err, ErrMaxBranches) {
+		t.Fatalf("expecting ErrMaxBranches got %v", err)
+	}
+	if b := root.Branch(); !errors.Is(b.Cause(), ErrMaxBranches) {
+		t.Fatalf("Branch should return a rejected branch, got %v", b.Cause())
+	}
+
+	child.Prune()
+	<-child.Done()
+	if _, err := root.TryBranch(); err != nil {
+		t.Fatalf("pruned branches should free their slots, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package jungle
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrLinked is the cause used to prune a tree because the tree linked
+	// to it was pruned
+	ErrLinked = errors.New("jungle: linked tree was pruned")
+)
+
+// Link couples a and b, once either of them is pruned the other is pruned
+// too, with ErrLinked (wrapping the original cause, if any) as its Cause.
+//
+// Unlike a parent and its children, linked trees are peers and don't wait
+// for each other to be done.
+func Link(a, b Tree) {
+	go func() {
+		var from, to Tree
+		select {
+		case <-a.Pruned():
+			from, to = a, b
+		case <-b.Pruned():
+			from, to = b, a
+		}
+		// if both were pruned, the other one already ignores this
+		to.PruneWith(linkedCause(from.Cause()))
+	}()
+}
+
+func linkedCause(cause error) error {
+	if cause == nil {
+		return ErrLinked
+	}
+	return fmt.Errorf("%w: %w", ErrLinked, cause)
+}
@@ -0,0 +1,31 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLink(t *testing.T) {
+	failure := errors.New("peer failed")
+	for _, pruneA := range []bool{true, false} {
+		a, b := Root().Branch(), Root().Branch()
+		Link(a, b)
+		pruned, other := a, b
+		if !pruneA {
+			pruned, other = b, a
+		}
+		pruned.PruneWith(failure)
+		select {
+		case <-other.Done():
+		case <-time.After(time.Second):
+			t.Fatal("the linked tree should be pruned")
+		}
+		if !errors.Is(other.Cause(), ErrLinked) || !errors.Is(other.Cause(), failure) {
+			t.Fatalf("expecting ErrLinked wrapping the original cause got %v", other.Cause())
+		}
+		if !errors.Is(pruned.Cause(), failure) || errors.Is(pruned.Cause(), ErrLinked) {
+			t.Fatalf("the cause of the first tree should be kept, got %v", pruned.Cause())
+		}
+	}
+}
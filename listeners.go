@@ -0,0 +1,120 @@
+package jungle
+
+import "sort"
+
+type (
+	// ListenerID identifies a listener registered with AddListener
+	ListenerID uint64
+
+	// listeners of a tree, guarded by tree.mu
+	listeners struct {
+		next        ListenerID
+		fns         map[ListenerID]func(Event)
+		pending     []Event
+		dispatching bool
+		// closed once pending is empty, only when someone is flushing
+		drained chan Signal
+	}
+)
+
+// AddListener registers fn to receive the lifecycle events of this tree
+// which happen after the call.
+//
+// Listeners are called one at a time, in the order they were added, from a
+// goroutine dedicated to this tree and never from the lifecycle itself. A
+// slow listener delays the next events, and Done, since all events are
+// delivered before Done is closed. Listeners must not wait for Done.
+//
+// If the tree is already done, fn is called right away with all of its past
+// events and it is not registered.
+func (t *tree) AddListener(fn func(Event)) ListenerID {
+	t.mu.Lock()
+	l := &t.listeners
+	l.next++
+	id := l.next
+	if t.finished() {
+		past := append([]Event(nil), t.eventsLog...)
+		t.mu.Unlock()
+		for _, e := range past {
+			fn(e)
+		}
+		return id
+	}
+	defer t.mu.Unlock()
+	if l.fns == nil {
+		l.fns = make(map[ListenerID]func(Event))
+	}
+	l.fns[id] = fn
+	return id
+}
+
+// RemoveListener stops calling the listener with the given id, an event
+// that is being dispatched might still reach it.
+func (t *tree) RemoveListener(id ListenerID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.listeners.fns, id)
+}
+
+// queueForListeners schedules e to be delivered to the current listeners,
+// t.mu must be held
+func (t *tree) queueForListeners(e Event) {
+	l := &t.listeners
+	if len(l.fns) == 0 {
+		return
+	}
+	l.pending = append(l.pending, e)
+	if !l.dispatching {
+		l.dispatching = true
+		go t.dispatch()
+	}
+}
+
+// dispatch delivers pending events until there is none left
+func (t *tree) dispatch() {
+	for {
+		t.mu.Lock()
+		l := &t.listeners
+		if len(l.pending) == 0 {
+			l.dispatching = false
+			if l.drained != nil {
+				close(l.drained)
+				l.drained = nil
+			}
+			t.mu.Unlock()
+			return
+		}
+		e := l.pending[0]
+		l.pending = l.pending[1:]
+		ids := make([]ListenerID, 0, len(l.fns))
+		for id := range l.fns {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		fns := make([]func(Event), len(ids))
+		for i, id := range ids {
+			fns[i] = l.fns[id]
+		}
+		t.mu.Unlock()
+
+		for _, fn := range fns {
+			fn(e)
+		}
+	}
+}
+
+// flushListeners waits until all queued events were delivered
+func (t *tree) flushListeners() {
+	t.mu.Lock()
+	l := &t.listeners
+	if !l.dispatching {
+		t.mu.Unlock()
+		return
+	}
+	if l.drained == nil {
+		l.drained = make(chan Signal)
+	}
+	drained := l.drained
+	t.mu.Unlock()
+	<-drained
+}
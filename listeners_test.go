@@ -0,0 +1,98 @@
+package jungle
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	eventRecorder struct {
+		sync.Mutex
+		kinds []EventKind
+		done  chan Signal
+	}
+)
+
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{done: make(chan Signal)}
+}
+
+func (r *eventRecorder) listen(e Event) {
+	r.Lock()
+	defer r.Unlock()
+	r.kinds = append(r.kinds, e.Kind)
+	if e.Kind == Pruned {
+		close(r.done)
+	}
+}
+
+func (r *eventRecorder) wait(t *testing.T) []EventKind {
+	t.Helper()
+	select {
+	case <-r.done:
+	case <-time.After(time.Second):
+		t.Fatal("listener should receive the Pruned event")
+	}
+	r.Lock()
+	defer r.Unlock()
+	return r.kinds
+}
+
+func TestAddRemoveListener(t *testing.T) {
+	branch := Root().Branch()
+	kept := newEventRecorder()
+	removed := newEventRecorder()
+	branch.AddListener(kept.listen)
+	id := branch.AddListener(removed.listen)
+	branch.RemoveListener(id)
+
+	branch.Prune()
+	if kinds := kept.wait(t); len(kinds) != 2 || kinds[0] != PruneStarted || kinds[1] != Pruned {
+		t.Fatalf("expecting PruneStarted and Pruned got %v", kinds)
+	}
+	removed.Lock()
+	defer removed.Unlock()
+	if len(removed.kinds) != 0 {
+		t.Fatalf("removed listener should not receive events, got %v", removed.kinds)
+	}
+}
+
+func TestAddListenerAfterDone(t *testing.T) {
+	branch := Root().Branch()
+	branch.Prune()
+	<-branch.Done()
+	late := newEventRecorder()
+	branch.AddListener(late.listen)
+	if kinds := late.wait(t); len(kinds) != 2 || kinds[1] != Pruned {
+		t.Fatalf("late listener should receive the terminal events, got %v", kinds)
+	}
+}
+
+func TestListenersFlushedBeforeDone(t *testing.T) {
+	branch := Root().Branch()
+	var mu sync.Mutex
+	var kinds []EventKind
+	branch.AddListener(func(e Event) {
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		kinds = append(kinds, e.Kind)
+	})
+	events := branch.Events()
+	branch.Prune()
+	<-branch.Done()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(kinds) != 2 || kinds[1] != Pruned {
+		t.Fatalf("slow listener should receive all events before Done, got %v", kinds)
+	}
+	var got []EventKind
+	for e := range events {
+		got = append(got, e.Kind)
+	}
+	if len(got) != 2 || got[1] != Pruned {
+		t.Fatalf("events channel should hold the terminal events, got %v", got)
+	}
+}
@@ -0,0 +1,78 @@
+package jungle
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+type (
+	// Logger receives the lifecycle events of all trees, implementations
+	// must be safe for concurrent use
+	Logger interface {
+		LogEvent(pid uint64, name string, e Event)
+	}
+
+	loggerHolder struct {
+		Logger
+	}
+
+	nopLogger struct{}
+
+	slogLogger struct {
+		logger *slog.Logger
+	}
+)
+
+var (
+	logger atomic.Value
+)
+
+// SetLogger changes the Logger used by all trees, a nil value
+// disables logging.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	logger.Store(loggerHolder{l})
+}
+
+// SetSlogLogger makes all trees log their lifecycle events to l, at debug
+// level, with pid, name and event attributes. Abandoned is logged at warn
+// level.
+func SetSlogLogger(l *slog.Logger) {
+	if l == nil {
+		SetLogger(nil)
+		return
+	}
+	SetLogger(slogLogger{logger: l})
+}
+
+func currentLogger() Logger {
+	if h, ok := logger.Load().(loggerHolder); ok {
+		return h.Logger
+	}
+	return nopLogger{}
+}
+
+func (nopLogger) LogEvent(uint64, string, Event) {}
+
+func (s slogLogger) LogEvent(pid uint64, name string, e Event) {
+	ctx := context.Background()
+	level := slog.LevelDebug
+	if e.Kind == Abandoned {
+		level = slog.LevelWarn
+	}
+	if !s.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.Uint64("pid", pid),
+		slog.String("name", name),
+		slog.String("event", e.Kind.String()),
+	}
+	if e.Cause != nil {
+		attrs = append(attrs, slog.String("cause", e.Cause.Error()))
+	}
+	s.logger.LogAttrs(ctx, level, "jungle lifecycle event", attrs...)
+}
@@ -0,0 +1,134 @@
+package jungle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	syncBuffer struct {
+		sync.Mutex
+		bytes.Buffer
+	}
+)
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.Buffer.Write(p)
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf syncBuffer
+	SetSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	branch := Root().Branch()
+	branch.SetName("worker")
+	branch.PruneWith(errors.New("bye"))
+	<-branch.Done()
+
+	// Pruned is emitted right after Done is closed, give it a moment
+	var events []string
+	deadline := time.Now().Add(time.Second)
+	for len(events) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		events = branchEvents(t, &buf, branch)
+	}
+	if len(events) != 2 || events[0] != "PruneStarted" || events[1] != "Pruned" {
+		t.Fatalf("expecting PruneStarted and Pruned got %v", events)
+	}
+}
+
+// branchEvents returns the events logged to buf for branch, in order
+func branchEvents(t *testing.T, buf *syncBuffer, branch Tree) []string {
+	buf.Lock()
+	defer buf.Unlock()
+	var events []string
+	dec := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for dec.More() {
+		var line map[string]interface{}
+		if err := dec.Decode(&line); err != nil {
+			t.Fatal(err)
+		}
+		if line["pid"] != float64(branch.PID()) {
+			continue
+		}
+		if line["name"] != "worker" || line["cause"] != "bye" || line["level"] != "DEBUG" {
+			t.Fatalf("invalid log line %v", line)
+		}
+		events = append(events, line["event"].(string))
+	}
+	return events
+}
+
+type (
+	recordLogger struct {
+		sync.Mutex
+		events []Event
+	}
+)
+
+func (r *recordLogger) LogEvent(pid uint64, name string, e Event) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recordLogger) find(kind EventKind, pid uint64) (Event, bool) {
+	r.Lock()
+	defer r.Unlock()
+	for _, e := range r.events {
+		if e.Kind == kind && e.PID == pid {
+			return e, true
+		}
+	}
+	return Event{}, false
+}
+
+func TestLoggerAbandoned(t *testing.T) {
+	rec := &recordLogger{}
+	SetLogger(rec)
+	defer SetLogger(nil)
+
+	root := New(WithTeardownTimeout(10 * time.Millisecond))
+	release := make(chan Signal)
+	defer close(release)
+	stuck := root.BranchFunc(func(Tree) error {
+		<-release
+		return nil
+	})
+	root.Prune()
+	<-root.Done()
+	e, ok := rec.find(Abandoned, stuck.PID())
+	if !ok || e.Cause != ErrTeardownTimeout {
+		t.Fatalf("expecting an Abandoned event for %v, got %v", stuck.PID(), e)
+	}
+}
+
+func TestTeardownTimeoutWarnsWithoutLogger(t *testing.T) {
+	var buf syncBuffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	root := New(WithTeardownTimeout(10 * time.Millisecond))
+	release := make(chan Signal)
+	defer close(release)
+	root.BranchFunc(func(Tree) error {
+		<-release
+		return nil
+	})
+	root.Prune()
+	<-root.Done()
+	buf.Lock()
+	defer buf.Unlock()
+	if !strings.Contains(buf.String(), "abandoning branches") {
+		t.Fatalf("expecting a warning, got %q", buf.String())
+	}
+}
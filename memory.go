@@ -0,0 +1,39 @@
+package jungle
+
+// SetMemoryReporter registers fn to report the estimated memory usage (in
+// bytes) of t, as seen by its own process function. jungle never measures
+// memory itself, it only sums what is reported, see SubtreeMemory.
+//
+// fn is called from whoever calls SubtreeMemory, so it must be safe for
+// concurrent use and cheap. A nil fn removes the reporter. Trees not
+// created by this package can't have a reporter.
+func SetMemoryReporter(t Tree, fn func() uint64) {
+	tt, ok := t.(*tree)
+	if !ok {
+		return
+	}
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.memory = fn
+}
+
+// SubtreeMemory returns the sum of the memory reported by t and all of its
+// live descendants, trees without a reporter count as zero, and so do
+// trees which were not created by this package.
+func SubtreeMemory(t Tree) uint64 {
+	tt, ok := t.(*tree)
+	if !ok {
+		return 0
+	}
+	var total uint64
+	tt.walk(func(c *tree) bool {
+		c.mu.Lock()
+		fn := c.memory
+		c.mu.Unlock()
+		if fn != nil {
+			total += fn()
+		}
+		return true
+	})
+	return total
+}
@@ -0,0 +1,39 @@
+package jungle
+
+import "testing"
+
+func TestSubtreeMemory(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	SetMemoryReporter(parent, func() uint64 { return 100 })
+	child := parent.Branch()
+	SetMemoryReporter(child, func() uint64 { return 20 })
+	SetMemoryReporter(child.Branch(), func() uint64 { return 3 })
+	// no reporter
+	parent.Branch()
+
+	if got := SubtreeMemory(parent); got != 123 {
+		t.Fatalf("expecting 123 got %v", got)
+	}
+	if got := SubtreeMemory(child); got != 23 {
+		t.Fatalf("expecting 23 got %v", got)
+	}
+	SetMemoryReporter(child, nil)
+	if got := SubtreeMemory(parent); got != 103 {
+		t.Fatalf("expecting 103 after removing a reporter got %v", got)
+	}
+}
+
+func TestSubtreeMemoryForeignTree(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	SetMemoryReporter(parent, func() uint64 { return 100 })
+	foreign := foreignTree{parent}
+	SetMemoryReporter(foreign, func() uint64 { return 1 })
+	if got := SubtreeMemory(foreign); got != 0 {
+		t.Fatalf("expecting 0 for a foreign tree got %v", got)
+	}
+	if got := SubtreeMemory(parent); got != 100 {
+		t.Fatalf("expecting 100 got %v", got)
+	}
+}
@@ -0,0 +1,65 @@
+package jungle
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Metrics receives measurements about the lifecycle of all trees,
+	// implementations must be safe for concurrent use and should return
+	// quickly since they are called from the hot path.
+	Metrics interface {
+		// BranchStartLatency reports how long it took between the branch
+		// creation and the moment its process function started running.
+		//
+		// Under load this reveals backpressure in the lifecycle.
+		BranchStartLatency(d time.Duration)
+	}
+
+	// LifecycleMetrics can be implemented by a Metrics to also be told
+	// when trees start, receive the prune signal and finish.
+	//
+	// BranchDone is only called for trees whose BranchStarted was called
+	// on the same Metrics, even if SetMetrics was called in between.
+	LifecycleMetrics interface {
+		BranchStarted()
+		BranchPruned()
+		BranchDone()
+	}
+
+	metricsHolder struct {
+		Metrics
+	}
+
+	nopMetrics struct{}
+)
+
+var (
+	metrics atomic.Value
+)
+
+// SetMetrics changes the Metrics used by all trees, a nil value
+// disables metrics.
+func SetMetrics(m Metrics) {
+	if m == nil {
+		m = nopMetrics{}
+	}
+	metrics.Store(metricsHolder{m})
+}
+
+func currentMetrics() Metrics {
+	if h, ok := metrics.Load().(metricsHolder); ok {
+		return h.Metrics
+	}
+	return nopMetrics{}
+}
+
+// currentLifecycleMetrics returns the current Metrics if it also
+// implements LifecycleMetrics
+func currentLifecycleMetrics() (LifecycleMetrics, bool) {
+	lm, ok := currentMetrics().(LifecycleMetrics)
+	return lm, ok
+}
+
+func (nopMetrics) BranchStartLatency(time.Duration) {}
@@ -0,0 +1,72 @@
+package jungle
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	recordMetrics struct {
+		sync.Mutex
+		latencies []time.Duration
+	}
+)
+
+func (r *recordMetrics) BranchStartLatency(d time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+	r.latencies = append(r.latencies, d)
+}
+
+func TestBranchStartLatency(t *testing.T) {
+	rec := &recordMetrics{}
+	SetMetrics(rec)
+	defer SetMetrics(nil)
+
+	localRoot := Root().Branch()
+	for i := 0; i < 1000; i++ {
+		localRoot.BranchFunc(func(Tree) error { return nil })
+	}
+	localRoot.Prune()
+	<-localRoot.Done()
+
+	rec.Lock()
+	defer rec.Unlock()
+	if len(rec.latencies) < 1000 {
+		t.Fatalf("should record at least 1000 latencies got %v", len(rec.latencies))
+	}
+	var max time.Duration
+	for _, l := range rec.latencies {
+		if l < 0 {
+			t.Fatalf("latency should never be negative, got %v", l)
+		}
+		if l > max {
+			max = l
+		}
+	}
+	t.Logf("max start latency with 1000 branches: %v", max)
+}
+
+func BenchmarkBranchStartLatency(b *testing.B) {
+	rec := &recordMetrics{}
+	SetMetrics(rec)
+	defer SetMetrics(nil)
+
+	localRoot := Root().Branch()
+	for i := 0; i < b.N; i++ {
+		localRoot.BranchFunc(func(Tree) error { return nil })
+	}
+	localRoot.Prune()
+	<-localRoot.Done()
+
+	rec.Lock()
+	defer rec.Unlock()
+	var total time.Duration
+	for _, l := range rec.latencies {
+		total += l
+	}
+	if len(rec.latencies) > 0 {
+		b.ReportMetric(float64(total)/float64(len(rec.latencies)), "ns/start")
+	}
+}
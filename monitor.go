@@ -0,0 +1,20 @@
+package jungle
+
+// Monitor returns a channel which receives the error returned by target
+// (nil included) once it is done, the channel is closed right after.
+//
+// Unlike Link, the lifecycles are not coupled: watcher is just notified.
+// If watcher is pruned before target is done the monitor stops and the
+// channel is closed without a value.
+func Monitor(watcher, target Tree) <-chan error {
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		select {
+		case <-target.Done():
+			ch <- target.Err()
+		case <-watcher.Pruned():
+		}
+	}()
+	return ch
+}
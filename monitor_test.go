@@ -0,0 +1,50 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMonitor(t *testing.T) {
+	watcher := Root().Branch()
+	defer watcher.Prune()
+	failure := errors.New("target failed")
+	release := make(chan Signal)
+	target := Root().BranchFunc(func(Tree) error {
+		<-release
+		return failure
+	})
+	ch := Monitor(watcher, target)
+	close(release)
+	select {
+	case err := <-ch:
+		if !errors.Is(err, failure) {
+			t.Fatalf("expecting the target error got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watcher should be notified")
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("channel should be closed after the notification")
+	}
+	if watcher.IsPruned() {
+		t.Fatal("the watcher should not be affected")
+	}
+}
+
+func TestMonitorWatcherPruned(t *testing.T) {
+	watcher := Root().Branch()
+	target := Root().Branch()
+	defer target.Prune()
+	ch := Monitor(watcher, target)
+	watcher.Prune()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("no value should be sent once the watcher is pruned")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel should be closed")
+	}
+}
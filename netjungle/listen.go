@@ -0,0 +1,46 @@
+// Package netjungle ties the lifecycle of network listeners to a jungle
+// tree.
+package netjungle
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/andrebq/jungle"
+)
+
+type (
+	listener struct {
+		net.Listener
+		tree jungle.Tree
+	}
+)
+
+var (
+	// ErrPruned is returned by Accept once the tree which owns the
+	// listener was pruned
+	ErrPruned = errors.New("netjungle: listener pruned")
+)
+
+// Listen announces on the local network address, just like net.Listen,
+// and closes the listener once t is pruned, unblocking Accept.
+//
+// After that, Accept returns an error matching both ErrPruned and
+// net.ErrClosed.
+func Listen(t jungle.Tree, network, addr string) (net.Listener, error) {
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	jungle.RegisterCloser(t, l)
+	return &listener{Listener: l, tree: t}, nil
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil && l.tree.IsPruned() {
+		return nil, fmt.Errorf("%w: %w", ErrPruned, err)
+	}
+	return conn, err
+}
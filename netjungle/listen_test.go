@@ -0,0 +1,32 @@
+package netjungle
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/andrebq/jungle"
+)
+
+func TestListen(t *testing.T) {
+	branch := jungle.Root().Branch()
+	l, err := Listen(branch, "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := make(chan error, 1)
+	go func() {
+		_, err := l.Accept()
+		accepted <- err
+	}()
+	branch.Prune()
+	select {
+	case err := <-accepted:
+		if !errors.Is(err, ErrPruned) || !errors.Is(err, net.ErrClosed) {
+			t.Fatalf("expecting ErrPruned and net.ErrClosed got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Accept should unblock once the tree is pruned")
+	}
+}
@@ -0,0 +1,71 @@
+package jungle
+
+import "sync"
+
+type (
+	// onceRegistry holds the sync.Once of each key used with Once
+	onceRegistry struct {
+		mu    sync.Mutex
+		onces map[interface{}]*sync.Once
+	}
+)
+
+var (
+	onceKey = NewValueKey[*onceRegistry]("jungle.once")
+	// onceMu serializes the lazy creation of registries at the root
+	onceMu sync.Mutex
+)
+
+// OnceScope makes t hold its own Once registry, so calls to Once from t and
+// its descendants are independent from the ones outside of t.
+func OnceScope(t Tree) {
+	onceKey.Set(t, &onceRegistry{})
+}
+
+// Once calls fn only once for the given key, no matter how many times and
+// from how many goroutines Once is called with t or any other tree sharing
+// the same registry. Concurrent callers wait until fn returns.
+//
+// The registry belongs to the closest ancestor of t that called OnceScope,
+// or to the root if none did. key must be comparable.
+//
+// Trees not created by this package have no registry, fn is called every
+// time Once is called with one of them.
+func Once(t Tree, key interface{}, fn func()) {
+	tt, ok := t.(*tree)
+	if !ok {
+		fn()
+		return
+	}
+	onceRegistryOf(tt).get(key).Do(fn)
+}
+
+// onceRegistryOf returns the registry used by t, creating one at the root
+// if needed
+func onceRegistryOf(t *tree) *onceRegistry {
+	if r, ok := onceKey.Get(t); ok {
+		return r
+	}
+	onceMu.Lock()
+	defer onceMu.Unlock()
+	if r, ok := onceKey.Get(t); ok {
+		return r
+	}
+	r := &onceRegistry{}
+	onceKey.Set(t.root, r)
+	return r
+}
+
+func (r *onceRegistry) get(key interface{}) *sync.Once {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.onces == nil {
+		r.onces = make(map[interface{}]*sync.Once)
+	}
+	o, ok := r.onces[key]
+	if !ok {
+		o = &sync.Once{}
+		r.onces[key] = o
+	}
+	return o
+}
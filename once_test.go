@@ -0,0 +1,44 @@
+package jungle
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOnce(t *testing.T) {
+	root := New()
+	defer root.Prune()
+	var warmed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		root.BranchFunc(func(t Tree) error {
+			defer wg.Done()
+			Once(t, "cache", func() { warmed.Add(1) })
+			return nil
+		})
+	}
+	wg.Wait()
+	if warmed.Load() != 1 {
+		t.Fatalf("fn should run once, ran %v times", warmed.Load())
+	}
+
+	scoped := root.Branch()
+	OnceScope(scoped)
+	Once(scoped.Branch(), "cache", func() { warmed.Add(1) })
+	Once(scoped, "cache", func() { warmed.Add(1) })
+	if warmed.Load() != 2 {
+		t.Fatalf("a new scope should have its own registry, ran %v times", warmed.Load())
+	}
+}
+
+func TestOnceForeignTree(t *testing.T) {
+	foreign := foreignTree{Root()}
+	var calls int
+	Once(foreign, "foreign", func() { calls++ })
+	Once(foreign, "foreign", func() { calls++ })
+	if calls != 2 {
+		t.Fatalf("trees from other implementations have no registry, expecting 2 calls got %v", calls)
+	}
+}
@@ -0,0 +1,67 @@
+package jungle
+
+import "time"
+
+type (
+	// Named is implemented by trees which carry a label, every Tree does
+	Named interface {
+		// Name returns the label given to this tree via SetName
+		Name() string
+
+		// SetName changes the label of this tree
+		SetName(name string)
+	}
+
+	// Deadlined is implemented by trees which are pruned automatically
+	// at some point in time
+	Deadlined interface {
+		// Deadline returns the moment the tree will be pruned, either due
+		// to its own deadline/lifetime or one of its ancestors
+		Deadline() (time.Time, bool)
+	}
+
+	// Restartable is implemented by supervised trees
+	Restartable interface {
+		// ReplaceProcess changes the function used in the next restart,
+		// the current execution is not interrupted
+		ReplaceProcess(fn func(Tree) error) error
+	}
+)
+
+// Deadline returns the earliest moment this tree will be pruned due to its
+// own deadline/lifetime or the ones from its ancestors.
+func (t *tree) Deadline() (time.Time, bool) {
+	return t.effectiveDeadline()
+}
+
+// AsNamed returns t as a Named tree, but only if t was actually given a
+// name
+func AsNamed(t Tree) (Named, bool) {
+	if t == nil || t.Name() == "" {
+		return nil, false
+	}
+	return t, true
+}
+
+// AsDeadlined returns t as a Deadlined tree, but only if t actually
+// has a deadline
+func AsDeadlined(t Tree) (Deadlined, bool) {
+	d, ok := t.(Deadlined)
+	if !ok {
+		return nil, false
+	}
+	if _, has := d.Deadline(); !has {
+		return nil, false
+	}
+	return d, true
+}
+
+// AsRestartable returns t as a Restartable tree, but only if t was
+// created by BranchSupervised
+func AsRestartable(t Tree) (Restartable, bool) {
+	if tt, ok := t.(*tree); ok && tt.supervisor == nil {
+		return nil, false
+	}
+	r, ok := t.(Restartable)
+	return r, ok
+}
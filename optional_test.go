@@ -0,0 +1,42 @@
+package jungle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAsHelpers(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	if _, ok := AsNamed(localRoot); ok {
+		t.Fatal("tree without a name should not be named")
+	}
+	localRoot.SetName("local")
+	if n, ok := AsNamed(localRoot); !ok {
+		t.Fatal("tree with a name should be named")
+	} else if n.Name() != "local" {
+		t.Fatalf("expecting local got %v", n.Name())
+	}
+
+	if _, ok := AsDeadlined(localRoot); ok {
+		t.Fatal("tree without a deadline should not be deadlined")
+	}
+	deadline := time.Now().Add(time.Hour)
+	if d, ok := AsDeadlined(localRoot.BranchDeadline(deadline).Branch()); !ok {
+		t.Fatal("tree with a deadline should be deadlined")
+	} else if at, _ := d.Deadline(); !at.Equal(deadline) {
+		t.Fatalf("expecting deadline %v got %v", deadline, at)
+	}
+
+	if _, ok := AsRestartable(localRoot); ok {
+		t.Fatal("tree which is not supervised should not be restartable")
+	}
+	supervised := localRoot.BranchSupervised(func(b Tree) error {
+		<-b.Pruned()
+		return nil
+	}, RestartPolicy{})
+	if _, ok := AsRestartable(supervised); !ok {
+		t.Fatal("supervised tree should be restartable")
+	}
+}
@@ -0,0 +1,102 @@
+package jungle
+
+import "time"
+
+type (
+	// RootOption configures a root created by New, all the branches
+	// under that root share the same configuration
+	RootOption func(*rootConfig)
+
+	rootConfig struct {
+		controlBuffer      int
+		ignoreCancelErrors bool
+		panicIsolation     bool
+		pruneWave          int
+		pruneWaveInterval  time.Duration
+		teardownTimeout    time.Duration
+		goroutineLabels    bool
+		pruneOrder         PruneOrder
+		clock              Clock
+	}
+)
+
+var (
+	defaultConfig = &rootConfig{}
+)
+
+// WithControlBuffer sets the buffer size of the internal channels used
+// to create and collect branches, trading memory for less blocking when
+// many branches are created at once.
+//
+// With a buffer, a branch might be returned before its parent is aware of
+// it, so it might not be visible to Walk right away. The default is zero
+// (unbuffered).
+func WithControlBuffer(n int) RootOption {
+	return func(c *rootConfig) {
+		if n < 0 {
+			n = 0
+		}
+		c.controlBuffer = n
+	}
+}
+
+// PendingBranches returns how many branches of t are waiting in the
+// control buffer for the lifecycle to start them. A value that keeps
+// growing means the lifecycle can't keep up with new branches. Trees not
+// created by this package always report zero.
+func PendingBranches(t Tree) int {
+	tt, ok := t.(*tree)
+	if !ok {
+		return 0
+	}
+	return len(tt.newBranch)
+}
+
+// WithIgnoreCancelErrors makes process functions which return
+// context.Canceled or context.DeadlineExceeded after their tree was pruned
+// be treated as if they returned nil, since they are just acknowledging the
+// prune. They are not reported by Err, Cause nor PruneCollect.
+//
+// Those errors are kept if the tree wasn't pruned yet.
+func WithIgnoreCancelErrors(ignore bool) RootOption {
+	return func(c *rootConfig) {
+		c.ignoreCancelErrors = ignore
+	}
+}
+
+// WithPruneWaves makes a tree deliver the prune signal to its children at
+// most size at a time, waiting interval between each wave, so a very wide
+// tree doesn't wake all of its process functions at once.
+//
+// It makes shutdown slower, and while a tree is sending its waves it
+// doesn't answer queries like Walk. A size of zero disables it.
+func WithPruneWaves(size int, interval time.Duration) RootOption {
+	return func(c *rootConfig) {
+		if size < 0 {
+			size = 0
+		}
+		c.pruneWave = size
+		c.pruneWaveInterval = interval
+	}
+}
+
+// WithPruneOrder changes the order in which a tree prunes its children,
+// see PruneOrder. The default is PruneConcurrent.
+func WithPruneOrder(order PruneOrder) RootOption {
+	return func(c *rootConfig) {
+		c.pruneOrder = order
+	}
+}
+
+// WithTeardownTimeout limits how long a pruned tree waits for its children
+// to be done. After d, the children still running are abandoned: the tree
+// is done without them and their goroutines are left behind. An Abandoned
+// event is sent to the Logger for each one, without a Logger they are
+// logged with slog at warn level. The default, zero, waits forever.
+//
+// The timeout doesn't apply to the process function of the tree itself.
+func WithTeardownTimeout(d time.Duration) RootOption {
+	return func(c *rootConfig) {
+		c.teardownTimeout = d
+	}
+}
@@ -0,0 +1,287 @@
+package jungle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithControlBuffer(t *testing.T) {
+	root := New(WithControlBuffer(16))
+	var count int
+	done := make(chan Signal, 100)
+	for i := 0; i < 100; i++ {
+		root.BranchFunc(func(Tree) error {
+			done <- Signal{}
+			return nil
+		})
+	}
+	for count < 100 {
+		<-done
+		count++
+	}
+	// buffered branches are still pruned
+	for i := 0; i < 100; i++ {
+		root.Branch()
+	}
+	root.Prune()
+	<-root.Done()
+}
+
+func BenchmarkControlBuffer(b *testing.B) {
+	for _, size := range []int{0, 16, 128} {
+		b.Run(fmt.Sprintf("buffer-%v", size), func(b *testing.B) {
+			root := New(WithControlBuffer(size))
+			for i := 0; i < b.N; i++ {
+				root.BranchFunc(func(Tree) error { return nil })
+			}
+			root.Prune()
+			<-root.Done()
+		})
+	}
+}
+
+func TestWithIgnoreCancelErrors(t *testing.T) {
+	root := New(WithIgnoreCancelErrors(true))
+	genuine := errors.New("genuine")
+	acknowledged := root.BranchFuncCtx(func(ctx context.Context, _ Tree) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	failed := root.BranchFunc(func(b Tree) error {
+		<-b.Pruned()
+		return genuine
+	})
+	early := root.BranchFunc(func(Tree) error {
+		return context.Canceled
+	})
+	<-early.Done()
+	if early.Err() != context.Canceled {
+		t.Fatalf("errors before prune should be kept, got %v", early.Err())
+	}
+
+	errs := root.PruneCollect()
+	if acknowledged.Err() != nil {
+		t.Fatalf("cancel errors after prune should be ignored, got %v", acknowledged.Err())
+	}
+	if failed.Err() != genuine {
+		t.Fatalf("expecting %v got %v", genuine, failed.Err())
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], genuine) {
+		t.Fatalf("only the genuine error should be collected, got %v", errs)
+	}
+
+	// without the option errors are kept
+	root = New()
+	canceled := root.BranchFuncCtx(func(ctx context.Context, _ Tree) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	root.Prune()
+	<-canceled.Done()
+	if canceled.Err() != context.Canceled {
+		t.Fatalf("expecting context.Canceled got %v", canceled.Err())
+	}
+}
+
+func TestWithPruneWaves(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	root := New(WithPruneWaves(2, interval))
+	var mu sync.Mutex
+	var woke []time.Time
+	ready := make(chan Signal, 6)
+	for i := 0; i < 6; i++ {
+		root.BranchFunc(func(t Tree) error {
+			ready <- Signal{}
+			<-t.Pruned()
+			mu.Lock()
+			defer mu.Unlock()
+			woke = append(woke, time.Now())
+			return nil
+		})
+	}
+	for i := 0; i < 6; i++ {
+		<-ready
+	}
+	root.Prune()
+	<-root.Done()
+
+	sort.Slice(woke, func(i, j int) bool { return woke[i].Before(woke[j]) })
+	if spread := woke[5].Sub(woke[0]); spread < 2*interval {
+		t.Fatalf("3 waves should take at least %v, took %v", 2*interval, spread)
+	}
+}
+
+func TestWithPruneOrder(t *testing.T) {
+	for _, tc := range []struct {
+		order    PruneOrder
+		expected []int
+	}{
+		{PruneFIFO, []int{0, 1, 2, 3}},
+		{PruneLIFO, []int{3, 2, 1, 0}},
+	} {
+		root := New(WithPruneOrder(tc.order))
+		var mu sync.Mutex
+		var finished []int
+		ready := make(chan Signal, 4)
+		for i := 0; i < 4; i++ {
+			i := i
+			root.BranchFunc(func(t Tree) error {
+				ready <- Signal{}
+				<-t.Pruned()
+				// older branches are slower, so a concurrent prune
+				// would finish them in reverse order
+				time.Sleep(time.Duration(4-i) * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				finished = append(finished, i)
+				return nil
+			})
+		}
+		for i := 0; i < 4; i++ {
+			<-ready
+		}
+		root.Prune()
+		<-root.Done()
+		if !reflect.DeepEqual(finished, tc.expected) {
+			t.Fatalf("order %v: expecting %v got %v", tc.order, tc.expected, finished)
+		}
+	}
+}
+
+func TestDefaultPruneOrder(t *testing.T) {
+	root := New()
+	var wg sync.WaitGroup
+	wg.Add(4)
+	ready := make(chan Signal, 4)
+	for i := 0; i < 4; i++ {
+		root.BranchFunc(func(t Tree) error {
+			ready <- Signal{}
+			<-t.Pruned()
+			// only finishes once all siblings were pruned
+			wg.Done()
+			wg.Wait()
+			return nil
+		})
+	}
+	for i := 0; i < 4; i++ {
+		<-ready
+	}
+	root.Prune()
+	select {
+	case <-root.Done():
+	case <-time.After(time.Second):
+		t.Fatal("by default all children should be pruned without waiting for each other")
+	}
+}
+
+func TestPendingBranches(t *testing.T) {
+	root := New(WithControlBuffer(8))
+	defer root.Prune()
+	release := make(chan Signal)
+	blocked := make(chan Signal)
+	// keep the lifecycle busy so new branches wait in the buffer
+	go func() {
+		root.(*tree).inspect <- func(subtrees) {
+			close(blocked)
+			<-release
+		}
+	}()
+	<-blocked
+	for i := 0; i < 3; i++ {
+		root.Branch()
+	}
+	if n := PendingBranches(root); n != 3 {
+		t.Fatalf("expecting 3 pending branches got %v", n)
+	}
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for PendingBranches(root) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pending branches should drain, got %v", PendingBranches(root))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPendingBranchesForeignTree(t *testing.T) {
+	if n := PendingBranches(foreignTree{Root()}); n != 0 {
+		t.Fatalf("expecting 0 got %v", n)
+	}
+}
+
+func TestWithTeardownTimeout(t *testing.T) {
+	root := New(WithTeardownTimeout(20 * time.Millisecond))
+	release := make(chan Signal)
+	defer close(release)
+	stuck := root.BranchFunc(func(Tree) error {
+		<-release
+		return nil
+	})
+	root.Prune()
+	select {
+	case <-root.Done():
+	case <-time.After(time.Second):
+		t.Fatal("root should be done after the teardown timeout")
+	}
+	select {
+	case <-stuck.Done():
+		t.Fatal("the stuck branch should still be running")
+	default:
+	}
+}
+
+func TestPruneOrderWithTeardownTimeout(t *testing.T) {
+	for _, order := range []PruneOrder{PruneFIFO, PruneLIFO} {
+		root := New(WithPruneOrder(order), WithTeardownTimeout(20*time.Millisecond))
+		release := make(chan Signal)
+		first := root.BranchFunc(func(Tree) error {
+			<-release
+			return nil
+		})
+		second := root.BranchFunc(func(Tree) error {
+			<-release
+			return nil
+		})
+		root.Prune()
+		select {
+		case <-root.Done():
+		case <-time.After(time.Second):
+			t.Fatalf("order %v: a stuck child should not hold the prune past the teardown timeout", order)
+		}
+		if !first.IsPruned() || !second.IsPruned() {
+			t.Fatalf("order %v: every child should get the prune signal before being abandoned", order)
+		}
+		close(release)
+	}
+}
+
+func TestPruneCollectWithTeardownTimeout(t *testing.T) {
+	root := New(WithTeardownTimeout(20 * time.Millisecond))
+	release := make(chan Signal)
+	defer close(release)
+	root.BranchFunc(func(Tree) error {
+		<-release
+		return nil
+	})
+	failure := errors.New("failure")
+	root.BranchFunc(func(b Tree) error {
+		<-b.Pruned()
+		return failure
+	})
+	collected := make(chan []error, 1)
+	go func() { collected <- root.PruneCollect() }()
+	select {
+	case errs := <-collected:
+		if len(errs) != 1 || !errors.Is(errs[0], failure) {
+			t.Fatalf("expecting only the error of the branch which finished got %v", errs)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PruneCollect should not wait for abandoned branches")
+	}
+}
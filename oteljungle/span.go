@@ -0,0 +1,55 @@
+// Package oteljungle creates OpenTelemetry spans that mirror the
+// structure of a jungle tree.
+//
+// It lives in its own module so the core jungle package remains
+// free of dependencies.
+package oteljungle
+
+import (
+	"context"
+
+	"github.com/andrebq/jungle"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/andrebq/jungle/oteljungle"
+)
+
+// BranchSpan creates a new branch of parent, named name, and starts a span
+// which is a child of the span in ctx. The span ends once the branch is
+// done and records the prune cause, if any, as an error status.
+//
+// The returned context carries the new span, so branches created with it
+// become child spans, mirroring the tree structure. The branch carries the
+// span as well, so contexts from BranchFuncCtx on it or its descendants
+// continue the trace.
+func BranchSpan(ctx context.Context, parent jungle.Tree, name string) (context.Context, jungle.Tree) {
+	return BranchSpanWithTracer(ctx, otel.Tracer(instrumentationName), parent, name)
+}
+
+// BranchSpanWithTracer works like BranchSpan but uses the given tracer
+// instead of the global one.
+func BranchSpanWithTracer(ctx context.Context, tracer trace.Tracer, parent jungle.Tree, name string) (context.Context, jungle.Tree) {
+	ctx, span := tracer.Start(ctx, name)
+	branch := parent.BranchContext(ctx)
+	branch.SetName(name)
+	span.SetAttributes(
+		attribute.Int64("jungle.pid", int64(branch.PID())),
+		attribute.String("jungle.name", name),
+	)
+	go func() {
+		<-branch.Done()
+		if cause := branch.Cause(); cause != nil {
+			span.RecordError(cause)
+			span.SetStatus(codes.Error, cause.Error())
+		} else {
+			span.SetStatus(codes.Ok, "")
+		}
+		span.End()
+	}()
+	return ctx, branch
+}
@@ -0,0 +1,71 @@
+package oteljungle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrebq/jungle"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestBranchSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer("test")
+
+	root := jungle.New()
+	ctx, parent := BranchSpanWithTracer(context.Background(), tracer, root, "parent")
+	_, child := BranchSpanWithTracer(ctx, tracer, parent, "child")
+
+	cause := errors.New("shutdown")
+	parent.PruneWith(cause)
+	<-parent.Done()
+	<-child.Done()
+	root.Prune()
+	<-root.Done()
+
+	// spans end right after the branch is done, but not synchronously
+	deadline := time.Now().Add(time.Second)
+	for len(exporter.GetSpans()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	spans := map[string]tracetest.SpanStub{}
+	for _, s := range exporter.GetSpans() {
+		spans[s.Name] = s
+	}
+	if len(spans) != 2 {
+		t.Fatalf("expecting 2 spans got %v", len(spans))
+	}
+	p, c := spans["parent"], spans["child"]
+	if c.Parent.SpanID() != p.SpanContext.SpanID() {
+		t.Fatal("child span should be a child of the parent span")
+	}
+	if p.Status.Code != codes.Error || p.Status.Description != cause.Error() {
+		t.Fatalf("parent span should record the prune cause, got %v", p.Status)
+	}
+	if c.Status.Code != codes.Ok {
+		t.Fatalf("child span should be ok, got %v", c.Status)
+	}
+}
+
+func TestBranchSpanContext(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	tracer := provider.Tracer("test")
+	root := jungle.New()
+	defer root.Prune()
+
+	ctx, branch := BranchSpanWithTracer(context.Background(), tracer, root, "request")
+	spans := make(chan trace.SpanContext, 1)
+	<-branch.Branch().BranchFuncCtx(func(ctx context.Context, _ jungle.Tree) error {
+		spans <- trace.SpanContextFromContext(ctx)
+		return nil
+	}).Done()
+	if got, expected := <-spans, trace.SpanContextFromContext(ctx); !got.Equal(expected) {
+		t.Fatalf("expecting span %v got %v", expected.SpanID(), got.SpanID())
+	}
+}
@@ -0,0 +1,51 @@
+package jungle
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+type (
+	// PanicError is the cause used to prune a branch whose process
+	// function panicked under a root created with WithPanicIsolation, or
+	// the error of a supervised run with PanicAsError
+	PanicError struct {
+		// Value passed to panic
+		Value interface{}
+		// Stack of the goroutine when it panicked
+		Stack []byte
+	}
+)
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("jungle: process panicked: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic, if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// WithPanicIsolation recovers panics from the process functions of all
+// branches under the root, the branch which panicked is pruned with a
+// *PanicError and the rest of the process keeps running.
+//
+// Without it a panic crashes the whole process, as usual.
+func WithPanicIsolation() RootOption {
+	return func(c *rootConfig) {
+		c.panicIsolation = true
+	}
+}
+
+// call runs fn, recovering any panic if the root isolates them
+func (t *tree) call(fn processFunc) (err error) {
+	if t.config.panicIsolation {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+			}
+		}()
+	}
+	return fn(t)
+}
@@ -0,0 +1,36 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPanicIsolation(t *testing.T) {
+	sandbox := New(WithPanicIsolation())
+	defer sandbox.Prune()
+	host := New()
+	defer host.Prune()
+	service := host.BranchService(func(Tree) error { return nil })
+
+	plugin := sandbox.Branch()
+	failure := errors.New("faulty plugin")
+	faulty := plugin.BranchFunc(func(Tree) error {
+		panic(failure)
+	})
+	select {
+	case <-faulty.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the panicking branch should be pruned")
+	}
+	var perr *PanicError
+	if !errors.As(faulty.Err(), &perr) || !errors.Is(faulty.Err(), failure) {
+		t.Fatalf("expecting a PanicError wrapping the value got %v", faulty.Err())
+	}
+	if len(perr.Stack) == 0 {
+		t.Error("PanicError should have the stack")
+	}
+	if service.IsPruned() || plugin.IsPruned() || sandbox.IsPruned() {
+		t.Fatal("other trees should keep running")
+	}
+}
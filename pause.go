@@ -0,0 +1,59 @@
+package jungle
+
+import "errors"
+
+var (
+	// ErrPaused is the cause of branches created while their
+	// parent was paused
+	ErrPaused = errors.New("jungle: tree is paused")
+)
+
+// Pause makes this tree refuse new branches, without pruning anything.
+// Branches created while paused are returned already done, with ErrPaused
+// as their Cause and their process functions are never called.
+//
+// Process functions can watch Paused to quiesce their work while the tree is
+// paused. Pause only affects this tree, descendants can still branch.
+func (t *tree) Pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.paused {
+		return
+	}
+	t.paused = true
+	if t.pausedCh == nil {
+		t.pausedCh = make(chan Signal)
+	}
+	close(t.pausedCh)
+}
+
+// Resume allows new branches to be created after a Pause, the channel
+// returned by Paused is replaced by a new one, which is open.
+func (t *tree) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.paused {
+		return
+	}
+	t.paused = false
+	t.pausedCh = make(chan Signal)
+}
+
+// Paused returns a channel which is closed while the tree is paused.
+//
+// Since Resume replaces the channel, callers must call Paused again after
+// the tree is resumed, instead of holding on to the old channel.
+func (t *tree) Paused() <-chan Signal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pausedCh == nil {
+		t.pausedCh = make(chan Signal)
+	}
+	return t.pausedCh
+}
+
+func (t *tree) isPaused() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paused
+}
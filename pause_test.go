@@ -0,0 +1,50 @@
+package jungle
+
+import "testing"
+
+func isClosed(ch <-chan Signal) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	for i := 0; i < 2; i++ {
+		if isClosed(localRoot.Paused()) {
+			t.Fatal("tree should not be paused")
+		}
+		localRoot.Pause()
+		paused := localRoot.Paused()
+		if !isClosed(paused) {
+			t.Fatal("tree should be paused")
+		}
+
+		var called bool
+		refused := localRoot.BranchFunc(func(Tree) error {
+			called = true
+			return nil
+		})
+		<-refused.Done()
+		if refused.Cause() != ErrPaused {
+			t.Fatalf("expecting ErrPaused got %v", refused.Cause())
+		}
+		if called {
+			t.Fatal("process of a refused branch should never be called")
+		}
+
+		localRoot.Resume()
+		if isClosed(localRoot.Paused()) {
+			t.Fatal("tree should be resumed")
+		}
+		accepted := localRoot.Branch()
+		if isClosed(accepted.Pruned()) {
+			t.Fatal("branches should be accepted after resume")
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package jungle
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+var (
+	sequentialPIDs atomic.Bool
+)
+
+// SetSequentialPIDMode makes roots created after the call (with New) number
+// their own trees, starting at 1 for the root and following the order in
+// which branches are created. Tests can then assert exact PIDs regardless
+// of what else ran in the process.
+//
+// PIDs are only unique within each root while enabled, and concurrent
+// branch creation still makes the order unpredictable, so this is meant
+// for tests only. Enabling it logs a warning.
+func SetSequentialPIDMode(enabled bool) {
+	if enabled && !sequentialPIDs.Load() {
+		slog.Warn("jungle: sequential PID mode enabled, PIDs are only unique within each root")
+	}
+	sequentialPIDs.Store(enabled)
+}
+
+// nextPID returns the pid of a new tree under parent
+func nextPID(parent *tree) uint64 {
+	if parent != nil && parent.root.pids != nil {
+		return parent.root.pids.Add(1)
+	}
+	return atomic.AddUint64(&pid, 1)
+}
@@ -0,0 +1,25 @@
+package jungle
+
+import "testing"
+
+func TestSequentialPIDMode(t *testing.T) {
+	SetSequentialPIDMode(true)
+	defer SetSequentialPIDMode(false)
+
+	build := func() []uint64 {
+		root := New()
+		defer root.Prune()
+		a := root.Branch()
+		b := a.Branch()
+		c := root.BranchFunc(func(Tree) error { return nil })
+		return []uint64{root.PID(), a.PID(), b.PID(), c.PID()}
+	}
+	for i := 0; i < 2; i++ {
+		pids := build()
+		for j, pid := range pids {
+			if pid != uint64(j+1) {
+				t.Fatalf("run %v: expecting pids 1 to 4 got %v", i, pids)
+			}
+		}
+	}
+}
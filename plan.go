@@ -0,0 +1,82 @@
+package jungle
+
+type (
+	// Plan describes a subtree, its branch configuration, process function
+	// and children, without creating anything. Use Activate to start it.
+	Plan struct {
+		opts     BranchOptions
+		fn       processFunc
+		children []*Plan
+	}
+)
+
+// NewPlan returns a plan for a branch configured by opts running fn, with
+// the given children. fn might be nil for branches that only hold children,
+// in which case opts.Restart is ignored as there is nothing to restart.
+func NewPlan(opts BranchOptions, fn func(Tree) error, children ...*Plan) *Plan {
+	return &Plan{
+		opts:     opts,
+		fn:       fn,
+		children: append([]*Plan(nil), children...),
+	}
+}
+
+// Activate creates the whole subtree described by p under parent, parents
+// before their children. Process functions only start after every branch
+// was created, so no process sees a partial subtree.
+//
+// If any branch can't be created (eg.: parent is paused or pruned) the
+// branches created so far are pruned without running their process
+// functions, and the error is returned. ErrForeignTree is returned if
+// parent was not created by this package.
+func (p *Plan) Activate(parent Tree) (Tree, error) {
+	pt, ok := parent.(*tree)
+	if !ok {
+		return nil, ErrForeignTree
+	}
+	start := make(chan Signal)
+	top, err := p.instantiate(pt, start)
+	if err != nil {
+		if top != nil {
+			top.PruneWith(err)
+		}
+		return nil, err
+	}
+	close(start)
+	return top, nil
+}
+
+// instantiate creates the branches of p, their process functions wait
+// until start is closed
+func (p *Plan) instantiate(parent *tree, start <-chan Signal) (Tree, error) {
+	var fn processFunc
+	if p.fn != nil {
+		fn = func(t Tree) error {
+			select {
+			case <-start:
+			case <-t.Pruned():
+				return nil
+			}
+			return p.fn(t)
+		}
+	}
+	branch := parent.branchWith(p.opts, fn)
+	if branch.IsPruned() {
+		return nil, rejection(branch)
+	}
+	for _, c := range p.children {
+		if _, err := c.instantiate(branch.(*tree), start); err != nil {
+			return branch, err
+		}
+	}
+	return branch, nil
+}
+
+// rejection returns why t was pruned right as it was created
+func rejection(t Tree) error {
+	<-t.Done()
+	if err := t.Cause(); err != nil {
+		return err
+	}
+	return ErrParentPruned
+}
@@ -0,0 +1,103 @@
+package jungle
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPlanActivate(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+
+	var children int32
+	ready := make(chan int32, 1)
+	running := make(chan Signal, 2)
+	worker := func(t Tree) error {
+		atomic.AddInt32(&children, 1)
+		running <- Signal{}
+		<-t.Pruned()
+		return nil
+	}
+	plan := NewPlan(BranchOptions{Name: "service"}, func(t Tree) error {
+		// every child exists before any process runs
+		ready <- int32(len(t.(*tree).children()))
+		<-t.Pruned()
+		return nil
+	},
+		NewPlan(BranchOptions{Name: "worker"}, worker),
+		NewPlan(BranchOptions{Name: "worker"}, worker),
+	)
+	top, err := plan.Activate(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case n := <-ready:
+		if n != 2 {
+			t.Fatalf("expecting 2 children when the process starts got %v", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the top process should run")
+	}
+	if top.Name() != "service" {
+		t.Fatalf("unexpected name %q", top.Name())
+	}
+	<-running
+	<-running
+	top.Prune()
+	<-top.Done()
+	if atomic.LoadInt32(&children) != 2 {
+		t.Fatalf("expecting both children to run got %v", atomic.LoadInt32(&children))
+	}
+}
+
+func TestPlanRollback(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	var runs int32
+	plan := NewPlan(BranchOptions{}, func(t Tree) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	parent.Pause()
+	if _, err := plan.Activate(parent); !errors.Is(err, ErrPaused) {
+		t.Fatalf("expecting ErrPaused got %v", err)
+	}
+	if atomic.LoadInt32(&runs) != 0 {
+		t.Fatal("no process should run on a failed activation")
+	}
+}
+
+func TestPlanForeignParent(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	plan := NewPlan(BranchOptions{}, func(Tree) error {
+		t.Error("fn should not run under a foreign parent")
+		return nil
+	})
+	if _, err := plan.Activate(foreignTree{parent}); !errors.Is(err, ErrForeignTree) {
+		t.Fatalf("expecting ErrForeignTree got %v", err)
+	}
+}
+
+func TestPlanRestartWithoutProcess(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	plan := NewPlan(BranchOptions{Restart: &RestartPolicy{}}, nil,
+		NewPlan(BranchOptions{}, func(t Tree) error {
+			<-t.Pruned()
+			return nil
+		}))
+	top, err := plan.Activate(localRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if top.IsPruned() {
+		t.Fatalf("a holder branch should stay alive, got %v", top.Cause())
+	}
+	if top.(*tree).supervisor != nil {
+		t.Fatal("a branch without a process should not be supervised")
+	}
+}
@@ -0,0 +1,71 @@
+package jungle
+
+import "errors"
+
+var (
+	// ErrPreempted is the cause of branches pruned to make room for a
+	// branch with a higher priority, see BranchPreempt
+	ErrPreempted = errors.New("jungle: preempted by a higher priority branch")
+)
+
+// BranchPreempt creates a branch with the given priority. When the root of
+// this tree is at the limit set by SetMaxBranches, the direct child of this
+// tree with the lowest priority below the new one is pruned with
+// ErrPreempted and, once it is done, the new branch takes its place.
+//
+// Preemption is opt-in on both sides: only branches created by
+// BranchPreempt can be preempted, regular branches are never touched.
+// Among children with the same priority the newest one is preempted, as it
+// likely has done the least work.
+//
+// Only the direct children of this tree are candidates, even though the
+// limit counts every branch under the root, so unrelated subtrees are
+// never preempted.
+//
+// The call blocks until the preempted child is done, its slot is handed
+// over to the new branch so no other branch can take it meanwhile. If
+// there is nothing to preempt, the branch is rejected with ErrMaxBranches
+// like Branch would.
+func (t *tree) BranchPreempt(priority int) Tree {
+	branch := newTree(t, nil)
+	branch.preemptible = true
+	branch.priority = priority
+	if !t.admit(branch) {
+		return branch
+	}
+	for !t.reserve() {
+		victim := t.preemptCandidate(priority)
+		if victim == nil {
+			branch.reject(ErrMaxBranches)
+			return branch
+		}
+		if !victim.preempted.CompareAndSwap(false, true) {
+			// another call is preempting it
+			continue
+		}
+		// the root goes over its limit until victim is done and
+		// releases its slot, which then belongs to branch
+		t.root.live.Add(1)
+		victim.PruneWith(ErrPreempted)
+		<-victim.Done()
+		break
+	}
+	branch.counted = true
+	return t.attach(branch)
+}
+
+// preemptCandidate returns the preemptible direct child of t with the
+// lowest priority below the given one, or nil if there is none
+func (t *tree) preemptCandidate(priority int) *tree {
+	var victim *tree
+	for _, c := range t.children() {
+		if !c.preemptible || c.priority >= priority || c.preempted.Load() || c.IsPruned() {
+			continue
+		}
+		if victim == nil || c.priority < victim.priority ||
+			(c.priority == victim.priority && c.created.After(victim.created)) {
+			victim = c
+		}
+	}
+	return victim
+}
@@ -0,0 +1,75 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBranchPreempt(t *testing.T) {
+	root := New()
+	defer root.Prune()
+	root.SetMaxBranches(3)
+
+	regular := root.Branch()
+	low := root.BranchPreempt(1)
+	lowest := root.BranchPreempt(0)
+	if b := root.BranchPreempt(0); !errors.Is(b.Cause(), ErrMaxBranches) {
+		t.Fatalf("same priority should not preempt, got %v", b.Cause())
+	}
+
+	high := root.BranchPreempt(10)
+	if high.IsPruned() {
+		t.Fatalf("high priority branch should be alive, got %v", high.Cause())
+	}
+	if !errors.Is(lowest.Cause(), ErrPreempted) {
+		t.Fatalf("the lowest priority branch should be preempted, got %v", lowest.Cause())
+	}
+	if low.IsPruned() || regular.IsPruned() {
+		t.Fatal("only the lowest priority branch should be preempted")
+	}
+
+	if b := root.BranchPreempt(10); b.IsPruned() {
+		t.Fatalf("expecting low to make room, got %v", b.Cause())
+	}
+	if !errors.Is(low.Cause(), ErrPreempted) {
+		t.Fatalf("low should be preempted next, got %v", low.Cause())
+	}
+	if b := root.BranchPreempt(10); !errors.Is(b.Cause(), ErrMaxBranches) {
+		t.Fatalf("regular branches should never be preempted, got %v", b.Cause())
+	}
+	if regular.IsPruned() {
+		t.Fatal("regular branches should never be preempted")
+	}
+}
+
+func TestBranchPreemptHandsOverTheSlot(t *testing.T) {
+	root := New()
+	defer root.Prune()
+	root.SetMaxBranches(3)
+
+	victim := root.BranchPreempt(0)
+	releaseFirst, releaseSecond := make(chan Signal), make(chan Signal)
+	first := victim.BranchFunc(func(Tree) error {
+		<-releaseFirst
+		return nil
+	})
+	victim.BranchFunc(func(Tree) error {
+		<-releaseSecond
+		return nil
+	})
+
+	preempted := make(chan Tree, 1)
+	go func() { preempted <- root.BranchPreempt(10) }()
+	<-victim.Pruned()
+	close(releaseFirst)
+	<-first.Done()
+	// a slot was released while the victim is still pruning, but it
+	// belongs to the preempting branch
+	if b := root.Branch(); !errors.Is(b.Cause(), ErrMaxBranches) {
+		t.Fatalf("the slot of the victim should not be taken, got %v", b.Cause())
+	}
+	close(releaseSecond)
+	if b := <-preempted; b.IsPruned() {
+		t.Fatalf("the preempting branch should take the slot, got %v", b.Cause())
+	}
+}
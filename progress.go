@@ -0,0 +1,73 @@
+package jungle
+
+type (
+	// progress of a prune, guarded by tree.mu
+	progress struct {
+		ch       chan float64
+		last     float64
+		finished bool
+	}
+)
+
+// PruneProgress returns a channel which receives the fraction (0.0 to 1.0)
+// of the children of this tree that are done since the prune started. The
+// channel is closed once the tree is done, right after receiving 1.0.
+//
+// Only the latest value is kept, a slow reader skips intermediate values
+// but never sees them going backwards. All calls return the same channel.
+func (t *tree) PruneProgress() <-chan float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := &t.progress
+	if p.ch == nil {
+		p.ch = make(chan float64, 1)
+		if p.finished {
+			p.ch <- 1
+			close(p.ch)
+		} else if t.pruned.Load() {
+			p.ch <- p.last
+		}
+	}
+	return p.ch
+}
+
+// reportProgress publishes the fraction of completed children, called
+// only by the lifecycle. Branches which arrive after the prune raise total,
+// so the fraction is never allowed to drop below the last one published.
+func (t *tree) reportProgress(completed, total int) {
+	v := 1.0
+	if total > 0 {
+		v = float64(completed) / float64(total)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	v = max(t.progress.last, v)
+	t.progress.last = v
+	t.progress.replace(v)
+}
+
+// finishProgress publishes the final value and closes the channel
+func (t *tree) finishProgress() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := &t.progress
+	p.last = 1
+	p.finished = true
+	if p.ch != nil {
+		p.replace(1)
+		close(p.ch)
+	}
+}
+
+// replace sends v dropping any value not received yet, the lifecycle is
+// the only sender so this never blocks
+func (p *progress) replace(v float64) {
+	if p.ch == nil {
+		return
+	}
+	select {
+	case <-p.ch:
+	default:
+	}
+	p.ch <- v
+}
@@ -0,0 +1,99 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPruneProgress(t *testing.T) {
+	parent := Root().Branch()
+	progress := parent.PruneProgress()
+	for i := 0; i < 5; i++ {
+		d := time.Duration(i) * 5 * time.Millisecond
+		parent.BranchFunc(func(t Tree) error {
+			<-t.Pruned()
+			time.Sleep(d)
+			return nil
+		})
+	}
+	parent.Prune()
+
+	var values []float64
+	for v := range progress {
+		values = append(values, v)
+	}
+	if len(values) == 0 || values[len(values)-1] != 1 {
+		t.Fatalf("progress should end at 1.0, got %v", values)
+	}
+	for i := 1; i < len(values); i++ {
+		if values[i] < values[i-1] {
+			t.Fatalf("progress should never go backwards, got %v", values)
+		}
+	}
+	if len(values) < 3 {
+		t.Errorf("expecting intermediate values, got %v", values)
+	}
+
+	done := Root().Branch()
+	done.Prune()
+	<-done.Done()
+	late := done.PruneProgress()
+	if v, ok := <-late; !ok || v != 1 {
+		t.Fatalf("progress after done should be 1.0, got %v %v", v, ok)
+	}
+}
+
+func TestPruneProgressLateBranches(t *testing.T) {
+	parent := Root().Branch()
+	progress := parent.PruneProgress()
+	release := make(chan Signal)
+	parent.BranchFunc(func(t Tree) error {
+		<-t.Pruned()
+		return nil
+	})
+	parent.BranchFunc(func(Tree) error {
+		<-release
+		return nil
+	})
+	parent.Prune()
+	for v := range progress {
+		if v >= 0.5 {
+			break
+		}
+	}
+
+	// branches created after the prune might still reach the lifecycle,
+	// which raises the total used to compute the progress
+	lateRelease := make(chan Signal)
+	var late int
+	for i := 0; i < 1000 && late < 3; i++ {
+		b := parent.BranchFunc(func(Tree) error {
+			<-lateRelease
+			return nil
+		})
+		if !errors.Is(b.Cause(), ErrParentPruned) {
+			late++
+		}
+		// let the lifecycle get back to waiting for branches
+		time.Sleep(time.Millisecond)
+	}
+	if late < 3 {
+		t.Skip("not enough branches arrived after the prune")
+	}
+	close(release)
+	if v := <-progress; v < 0.5 {
+		t.Fatalf("progress went backwards to %v after late branches", v)
+	}
+	close(lateRelease)
+	last := 0.5
+	for v := range progress {
+		if v < last {
+			t.Fatalf("progress went backwards from %v to %v", last, v)
+		}
+		last = v
+	}
+	if last != 1 {
+		t.Fatalf("progress should end at 1.0, got %v", last)
+	}
+}
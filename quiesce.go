@@ -0,0 +1,53 @@
+package jungle
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// quiescing counts the Quiesce calls in progress, activity is only
+// recorded while there is at least one
+var quiescing atomic.Int32
+
+// Quiesce blocks until the subtree of t reaches a steady state, ie.: no
+// branch was created or completed anywhere in it for the quiet duration.
+// It returns false if that doesn't happen before timeout, and always for
+// trees which were not created by this package.
+//
+// Activity is only recorded while a Quiesce is waiting, so the quiet
+// period is counted from the call at the earliest. It is measured with the
+// clock of the root of t, see WithClock, while timeout is always real
+// time. Useful in tests and to wait for a warmup to finish.
+func Quiesce(t Tree, quiet, timeout time.Duration) bool {
+	tt, ok := t.(*tree)
+	if !ok {
+		return false
+	}
+	quiescing.Add(1)
+	defer quiescing.Add(-1)
+	tt.activity.Store(tt.config.now().UnixNano())
+	giveUp := time.Now().Add(timeout)
+	for {
+		idle := tt.config.now().Sub(time.Unix(0, tt.activity.Load()))
+		if idle >= quiet {
+			return true
+		}
+		wait := quiet - idle
+		if time.Now().Add(wait).After(giveUp) {
+			return false
+		}
+		time.Sleep(wait)
+	}
+}
+
+// touch records some activity in t and all of its ancestors, but only
+// while someone is waiting in Quiesce
+func (t *tree) touch() {
+	if quiescing.Load() == 0 {
+		return
+	}
+	now := t.config.now().UnixNano()
+	for p := t; p != nil; p = p.parent {
+		p.activity.Store(now)
+	}
+}
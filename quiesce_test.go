@@ -0,0 +1,57 @@
+package jungle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuiesce(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	stop := time.After(time.Millisecond * 100)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			localRoot.BranchFunc(func(Tree) error {
+				time.Sleep(time.Millisecond)
+				return nil
+			})
+		}
+	}()
+
+	if Quiesce(localRoot, time.Millisecond*50, time.Millisecond*50) {
+		t.Fatal("tree should not be quiet while branching")
+	}
+	start := time.Now()
+	if !Quiesce(localRoot, time.Millisecond*50, time.Second) {
+		t.Fatal("tree should be quiet after branching stops")
+	}
+	if elapsed := time.Since(start); elapsed < time.Millisecond*40 {
+		t.Fatalf("quiesce returned too early, after %v", elapsed)
+	}
+}
+
+func TestQuiesceWithClock(t *testing.T) {
+	root := New(WithClock(&stepClock{now: time.Now(), step: time.Hour}))
+	defer root.Prune()
+	if !Quiesce(root, time.Minute, time.Second) {
+		t.Fatal("the quiet period should be measured with the clock of the root")
+	}
+}
+
+// foreignTree is a Tree implemented outside of jungle
+type foreignTree struct {
+	Tree
+}
+
+func TestQuiesceForeignTree(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	if Quiesce(foreignTree{localRoot}, 0, time.Second) {
+		t.Fatal("trees from other implementations can't be observed")
+	}
+}
@@ -0,0 +1,79 @@
+package jungle
+
+import "time"
+
+type (
+	// Backoff returns how long to wait before the given retry, starting
+	// at 1 for the first retry
+	Backoff func(retry int) time.Duration
+)
+
+// ConstantBackoff waits d before every retry
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff waits base before the first retry and doubles the
+// wait on each retry, up to max
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(retry int) time.Duration {
+		d := base
+		for i := 1; i < retry && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// BranchRetry creates a new branch that runs fn, if fn returns an error it
+// is called again after the backoff, up to attempts times in total. Once fn
+// succeeds or the attempts are over the branch is pruned, with the last
+// error if any, just like BranchFunc.
+//
+// It is meant for short tasks, like idempotent setup, long lived processes
+// should use BranchSupervised. Each attempt runs on a fresh child of the
+// branch, which is pruned once fn returns. Pruning the branch stops the
+// retries. A nil backoff retries right away.
+func (t *tree) BranchRetry(fn func(Tree) error, attempts int, backoff Backoff) Tree {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return t.BranchFunc(func(b Tree) error {
+		branch := b.(*tree)
+		var err error
+		for i := 1; i <= attempts; i++ {
+			branch.attempts.Store(int32(i))
+			run := branch.Branch()
+			err = fn(run)
+			run.Prune()
+			<-run.Done()
+			if err == nil || i >= attempts {
+				return err
+			}
+			var wait time.Duration
+			if backoff != nil {
+				wait = backoff(i)
+			}
+			select {
+			case <-time.After(wait):
+			case <-branch.prune:
+				return err
+			}
+		}
+		return err
+	})
+}
+
+// Attempts returns how many times the function of a branch created by
+// BranchRetry was called so far, zero for other trees
+func Attempts(t Tree) int {
+	if tt, ok := t.(*tree); ok {
+		return int(tt.attempts.Load())
+	}
+	return 0
+}
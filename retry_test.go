@@ -0,0 +1,56 @@
+package jungle
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBranchRetry(t *testing.T) {
+	var calls atomic.Int32
+	branch := Root().BranchRetry(func(Tree) error {
+		if calls.Add(1) < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	}, 5, ConstantBackoff(time.Millisecond))
+	<-branch.Done()
+	if branch.Err() != nil || Attempts(branch) != 3 {
+		t.Fatalf("expecting success at the third attempt got %v after %v", branch.Err(), Attempts(branch))
+	}
+
+	failure := errors.New("always")
+	branch = Root().BranchRetry(func(Tree) error { return failure }, 2, nil)
+	<-branch.Done()
+	if !errors.Is(branch.Err(), failure) || Attempts(branch) != 2 {
+		t.Fatalf("expecting the last error after 2 attempts got %v after %v", branch.Err(), Attempts(branch))
+	}
+}
+
+func TestBranchRetryPruned(t *testing.T) {
+	branch := Root().BranchRetry(func(Tree) error {
+		return errors.New("fail")
+	}, 100, ConstantBackoff(time.Hour))
+	for Attempts(branch) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	branch.Prune()
+	select {
+	case <-branch.Done():
+	case <-time.After(time.Second):
+		t.Fatal("prune should stop the retries")
+	}
+	if Attempts(branch) != 1 {
+		t.Fatalf("expecting a single attempt got %v", Attempts(branch))
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff(time.Millisecond, 5*time.Millisecond)
+	for i, want := range []time.Duration{1, 2, 4, 5, 5} {
+		if got := b(i + 1); got != want*time.Millisecond {
+			t.Errorf("retry %v: expecting %v got %v", i+1, want*time.Millisecond, got)
+		}
+	}
+}
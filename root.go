@@ -0,0 +1,10 @@
+package jungle
+
+// RootOf returns the root of t, ie.: the tree without a parent that t
+// descends from, either Root or one created by New. A root is its own root.
+func RootOf(t Tree) Tree {
+	if tt, ok := t.(*tree); ok {
+		return tt.root
+	}
+	return t
+}
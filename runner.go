@@ -0,0 +1,81 @@
+package jungle
+
+import "sync"
+
+type (
+	// Runner executes process functions from many branches using a
+	// bounded number of goroutines.
+	//
+	// Branches created with RunOn still have their own lifecycle, but
+	// their process functions are queued until a worker is available,
+	// which reduces the number of goroutines of very large trees made
+	// of short tasks.
+	Runner struct {
+		max int
+
+		mu      sync.Mutex
+		workers int
+		queue   []func()
+	}
+)
+
+// NewRunner returns a Runner which uses at most workers goroutines, they
+// are started on demand and exit when there is nothing left to run, so
+// a Runner doesn't need to be closed.
+func NewRunner(workers int) *Runner {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Runner{max: workers}
+}
+
+// RunOn creates a new branch whose process function is executed by r.
+//
+// If the branch is pruned before a worker picks up fn, then fn is never
+// called. Otherwise it behaves exactly like BranchFunc, which means fn
+// should watch Pruned to return early.
+func (t *tree) RunOn(r *Runner, fn func(Tree) error) Tree {
+	branch := newTree(t, func(b Tree) error {
+		if !b.(*tree).alive() {
+			return nil
+		}
+		return fn(b)
+	})
+	branch.runner = r
+	return t.grow(branch)
+}
+
+// submit queues fn and never blocks, so it is safe to call from
+// the lifecycle
+func (r *Runner) submit(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queue = append(r.queue, fn)
+	if r.workers < r.max {
+		r.workers++
+		go r.work()
+	}
+}
+
+func (r *Runner) next() (func(), bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.queue) == 0 {
+		r.workers--
+		return nil, false
+	}
+	fn := r.queue[0]
+	r.queue[0] = nil
+	r.queue = r.queue[1:]
+	return fn, true
+}
+
+func (r *Runner) work() {
+	for {
+		fn, ok := r.next()
+		if !ok {
+			return
+		}
+		fn()
+	}
+}
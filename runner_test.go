@@ -0,0 +1,98 @@
+package jungle
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunOn(t *testing.T) {
+	localRoot := Root().Branch()
+	runner := NewRunner(4)
+	var running, peak, count int32
+	for i := 0; i < 100; i++ {
+		localRoot.RunOn(runner, func(Tree) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&count, 1)
+			return nil
+		})
+	}
+	deadline := time.After(time.Second * 5)
+	for atomic.LoadInt32(&count) != 100 {
+		select {
+		case <-deadline:
+			t.Fatalf("all functions should run, got %v", atomic.LoadInt32(&count))
+		case <-time.After(time.Millisecond):
+		}
+	}
+	if p := atomic.LoadInt32(&peak); p > 4 {
+		t.Fatalf("at most 4 functions should run at once, got %v", p)
+	}
+	localRoot.Prune()
+	<-localRoot.Done()
+}
+
+func TestRunOnPruned(t *testing.T) {
+	localRoot := Root().Branch()
+	runner := NewRunner(1)
+	release := make(chan Signal)
+	started := make(chan Signal)
+	localRoot.RunOn(runner, func(Tree) error {
+		close(started)
+		<-release
+		return nil
+	})
+	// the only worker is busy from now on
+	<-started
+	var called int32
+	queued := localRoot.RunOn(runner, func(Tree) error {
+		atomic.StoreInt32(&called, 1)
+		return nil
+	})
+	queued.Prune()
+	close(release)
+	<-queued.Done()
+	if atomic.LoadInt32(&called) != 0 {
+		t.Fatal("functions of pruned branches should not run")
+	}
+	localRoot.Prune()
+	<-localRoot.Done()
+}
+
+func benchmarkGoroutines(b *testing.B, branch func(Tree, func(Tree) error)) {
+	localRoot := Root().Branch()
+	release := make(chan Signal)
+	before := runtime.NumGoroutine()
+	for i := 0; i < b.N; i++ {
+		branch(localRoot, func(Tree) error {
+			<-release
+			return nil
+		})
+	}
+	b.ReportMetric(float64(runtime.NumGoroutine()-before)/float64(b.N), "goroutines/op")
+	close(release)
+	localRoot.Prune()
+	<-localRoot.Done()
+}
+
+func BenchmarkGoroutinesBranchFunc(b *testing.B) {
+	benchmarkGoroutines(b, func(t Tree, fn func(Tree) error) {
+		t.BranchFunc(fn)
+	})
+}
+
+func BenchmarkGoroutinesRunOn(b *testing.B) {
+	runner := NewRunner(runtime.NumCPU())
+	benchmarkGoroutines(b, func(t Tree, fn func(Tree) error) {
+		t.RunOn(runner, fn)
+	})
+}
@@ -0,0 +1,55 @@
+package jungle
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrScopeLeak is the panic value used by StrictScope when a branch is
+	// still running after the scope function returns
+	ErrScopeLeak = errors.New("jungle: branch outlived its scope")
+)
+
+// Scope creates a branch of parent and calls fn with it. Once fn returns
+// the branch is pruned and Scope waits until every branch created under it
+// is done, so nothing started inside fn outlives the call.
+func Scope(parent Tree, fn func(scope Tree)) {
+	scope := parent.Branch()
+	defer func() {
+		scope.Prune()
+		<-scope.Done()
+	}()
+	fn(scope)
+}
+
+// StrictScope works like Scope but panics with ErrScopeLeak if any branch
+// is still running when fn returns, instead of pruning it. Useful in tests
+// and debug builds to find code that doesn't wait for its branches.
+//
+// Branches of trees which were not created by this package can't be
+// inspected, StrictScope then works just like Scope.
+func StrictScope(parent Tree, fn func(scope Tree)) {
+	scope := parent.Branch()
+	defer func() {
+		scope.Prune()
+		<-scope.Done()
+	}()
+	fn(scope)
+	st, ok := scope.(*tree)
+	if !ok {
+		return
+	}
+	var running int
+	for _, c := range st.children() {
+		select {
+		case <-c.done:
+			// done, but its parent didn't notice yet
+		default:
+			running++
+		}
+	}
+	if running > 0 {
+		panic(fmt.Errorf("%w: %v branches still running", ErrScopeLeak, running))
+	}
+}
@@ -0,0 +1,65 @@
+package jungle
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScope(t *testing.T) {
+	var finished atomic.Int32
+	Scope(Root(), func(scope Tree) {
+		for i := 0; i < 3; i++ {
+			scope.BranchFunc(func(t Tree) error {
+				<-t.Pruned()
+				time.Sleep(10 * time.Millisecond)
+				finished.Add(1)
+				return nil
+			})
+		}
+	})
+	if finished.Load() != 3 {
+		t.Fatalf("all branches should be done when Scope returns, got %v", finished.Load())
+	}
+}
+
+func TestStrictScope(t *testing.T) {
+	StrictScope(Root(), func(scope Tree) {
+		<-scope.BranchFunc(func(Tree) error { return nil }).Done()
+	})
+
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrScopeLeak) {
+			t.Fatalf("expecting a panic with ErrScopeLeak got %v", err)
+		}
+	}()
+	StrictScope(Root(), func(scope Tree) {
+		scope.BranchFunc(func(t Tree) error {
+			<-t.Pruned()
+			return nil
+		})
+	})
+	t.Fatal("StrictScope should panic")
+}
+
+// foreignBranches is a foreign tree whose branches are foreign as well
+type foreignBranches struct {
+	foreignTree
+}
+
+func (f foreignBranches) Branch() Tree {
+	return foreignBranches{foreignTree{f.Tree.Branch()}}
+}
+
+func TestStrictScopeForeignParent(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	var scoped Tree
+	StrictScope(foreignBranches{foreignTree{parent}}, func(scope Tree) {
+		scoped = scope
+		scope.Branch()
+	})
+	<-scoped.Done()
+}
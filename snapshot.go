@@ -0,0 +1,296 @@
+package jungle
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type (
+	// TreeSnapshot is a point-in-time view of a tree and its live
+	// descendants, which can be serialized to JSON
+	TreeSnapshot struct {
+		PID      uint64
+		Name     string
+		Tags     map[string]string
+		State    State
+		Uptime   time.Duration
+		Children []TreeSnapshot
+	}
+
+	// State of a tree at the moment of a snapshot
+	State string
+
+	jsonSnapshot struct {
+		PID      uint64            `json:"pid"`
+		Name     string            `json:"name,omitempty"`
+		Tags     map[string]string `json:"tags,omitempty"`
+		State    State             `json:"state"`
+		Uptime   string            `json:"uptime"`
+		Children []TreeSnapshot    `json:"children,omitempty"`
+	}
+)
+
+const (
+	// snapshotVersion is the first byte of the binary encoding, it must
+	// change whenever a decoder of the previous version would misread
+	// the new format. Adding fields to the end of a node doesn't need a
+	// new version, older decoders skip them.
+	snapshotVersion = 1
+)
+
+var (
+	// ErrSnapshotVersion is returned by UnmarshalBinary for data encoded
+	// with an unknown version of the format
+	ErrSnapshotVersion = errors.New("jungle: unknown snapshot version")
+
+	// ErrInvalidSnapshot is returned by UnmarshalBinary for data which
+	// is truncated or corrupted
+	ErrInvalidSnapshot = errors.New("jungle: invalid snapshot")
+)
+
+const (
+	// StateAlive trees are running and accept new branches
+	StateAlive = State("alive")
+	// StatePruning trees received the prune signal but are not done yet
+	StatePruning = State("pruning")
+	// StateDone trees and all their children are finished
+	StateDone = State("done")
+)
+
+// Snapshot captures the current state of t and its live descendants,
+// the traversal is done via Walk so it is safe to use while the tree
+// changes, but the result might be stale as soon as it is returned.
+func Snapshot(t Tree) TreeSnapshot {
+	tt, ok := t.(*tree)
+	if !ok {
+		return TreeSnapshot{PID: t.PID(), Name: t.Name(), State: stateOf(t)}
+	}
+	now := time.Now()
+	nodes := make(map[*tree]*TreeSnapshot)
+	var order []*tree
+	tt.walk(func(c *tree) bool {
+		nodes[c] = &TreeSnapshot{
+			PID:    c.pid,
+			Name:   c.Name(),
+			Tags:   c.Tags(),
+			State:  stateOf(c),
+			Uptime: now.Sub(c.created),
+		}
+		order = append(order, c)
+		return true
+	})
+	// walk is depth-first, so children are only complete after all of
+	// their own descendants, build the nesting bottom-up
+	for i := len(order) - 1; i > 0; i-- {
+		c := order[i]
+		parent := nodes[c.parent]
+		parent.Children = append([]TreeSnapshot{*nodes[c]}, parent.Children...)
+	}
+	return *nodes[tt]
+}
+
+func stateOf(t Tree) State {
+	select {
+	case <-t.Done():
+		return StateDone
+	default:
+	}
+	select {
+	case <-t.Pruned():
+		return StatePruning
+	default:
+		return StateAlive
+	}
+}
+
+// MarshalJSON encodes the snapshot using lower case keys and a
+// human readable uptime
+func (s TreeSnapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonSnapshot{
+		PID:      s.PID,
+		Name:     s.Name,
+		Tags:     s.Tags,
+		State:    s.State,
+		Uptime:   s.Uptime.String(),
+		Children: s.Children,
+	})
+}
+
+// UnmarshalJSON decodes a snapshot encoded by MarshalJSON
+func (s *TreeSnapshot) UnmarshalJSON(data []byte) error {
+	var js jsonSnapshot
+	if err := json.Unmarshal(data, &js); err != nil {
+		return err
+	}
+	uptime, err := time.ParseDuration(js.Uptime)
+	if err != nil {
+		return err
+	}
+	*s = TreeSnapshot{
+		PID:      js.PID,
+		Name:     js.Name,
+		Tags:     js.Tags,
+		State:    js.State,
+		Uptime:   uptime,
+		Children: js.Children,
+	}
+	return nil
+}
+
+// MarshalBinary encodes the snapshot in a compact binary format, cheaper
+// than JSON for frequent sampling. The first byte is the version of the
+// format, followed by the root node.
+//
+// Each node is prefixed by its length and holds, in order: pid, name, tags
+// (sorted by key), state, uptime in nanoseconds and the children. Numbers
+// are varints and strings are prefixed by their length.
+func (s TreeSnapshot) MarshalBinary() ([]byte, error) {
+	return s.appendBinary([]byte{snapshotVersion}), nil
+}
+
+func (s TreeSnapshot) appendBinary(buf []byte) []byte {
+	var node []byte
+	node = binary.AppendUvarint(node, s.PID)
+	node = appendString(node, s.Name)
+	keys := make([]string, 0, len(s.Tags))
+	for k := range s.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	node = binary.AppendUvarint(node, uint64(len(keys)))
+	for _, k := range keys {
+		node = appendString(node, k)
+		node = appendString(node, s.Tags[k])
+	}
+	node = appendString(node, string(s.State))
+	node = binary.AppendVarint(node, int64(s.Uptime))
+	node = binary.AppendUvarint(node, uint64(len(s.Children)))
+	for _, c := range s.Children {
+		node = c.appendBinary(node)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(node)))
+	return append(buf, node...)
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// UnmarshalBinary decodes a snapshot encoded by MarshalBinary
+func (s *TreeSnapshot) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return ErrInvalidSnapshot
+	}
+	if data[0] != snapshotVersion {
+		return fmt.Errorf("%w: %v", ErrSnapshotVersion, data[0])
+	}
+	d := snapshotDecoder{data: data[1:]}
+	decoded := d.node()
+	if d.err != nil {
+		return d.err
+	}
+	*s = decoded
+	return nil
+}
+
+type (
+	// snapshotDecoder reads the binary encoding, once err is set every
+	// read returns zero values
+	snapshotDecoder struct {
+		data []byte
+		err  error
+	}
+)
+
+func (d *snapshotDecoder) node() TreeSnapshot {
+	size := d.uvarint()
+	if d.err != nil || size > uint64(len(d.data)) {
+		d.fail()
+		return TreeSnapshot{}
+	}
+	// fields added by newer encoders are left at the end of the node and
+	// skipped along with it
+	body := &snapshotDecoder{data: d.data[:size]}
+	d.data = d.data[size:]
+
+	var s TreeSnapshot
+	s.PID = body.uvarint()
+	s.Name = body.string()
+	if n := body.count(); n > 0 {
+		s.Tags = make(map[string]string, n)
+		for i := 0; i < n; i++ {
+			k := body.string()
+			s.Tags[k] = body.string()
+		}
+	}
+	s.State = State(body.string())
+	s.Uptime = time.Duration(body.varint())
+	if n := body.count(); n > 0 {
+		s.Children = make([]TreeSnapshot, n)
+		for i := range s.Children {
+			s.Children[i] = body.node()
+		}
+	}
+	if body.err != nil {
+		d.err = body.err
+	}
+	return s
+}
+
+func (d *snapshotDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *snapshotDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// count reads the length of a list, every item takes at least one byte
+// so longer lists are rejected before allocating them
+func (d *snapshotDecoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.fail()
+		return 0
+	}
+	return int(n)
+}
+
+func (d *snapshotDecoder) string() string {
+	n := d.count()
+	if d.err != nil {
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *snapshotDecoder) fail() {
+	if d.err == nil {
+		d.err = ErrInvalidSnapshot
+	}
+}
@@ -0,0 +1,93 @@
+package jungle
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	localRoot.SetName("local")
+	a := localRoot.Branch()
+	a.SetName("a")
+	a.Branch().SetName("a1")
+	a.Branch().SetName("a2")
+	localRoot.Branch().SetName("b")
+
+	snap := Snapshot(localRoot)
+	if snap.Name != "local" || snap.State != StateAlive || snap.PID != localRoot.PID() {
+		t.Fatalf("invalid root snapshot %#v", snap)
+	}
+	if len(snap.Children) != 2 || snap.Children[0].Name != "a" || snap.Children[1].Name != "b" {
+		t.Fatalf("invalid children %#v", snap.Children)
+	}
+	if len(snap.Children[0].Children) != 2 || snap.Children[0].Children[1].Name != "a2" {
+		t.Fatalf("invalid grandchildren %#v", snap.Children[0].Children)
+	}
+
+	buf, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded TreeSnapshot
+	if err := json.Unmarshal(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snap, decoded) {
+		t.Fatalf("round-trip failed\nexpected %#v\ngot %#v\njson %s", snap, decoded, buf)
+	}
+}
+
+func TestSnapshotBinary(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	localRoot.SetName("local")
+	a := localRoot.Branch()
+	a.SetName("a")
+	a.SetTag("role", "worker")
+	a.SetTag("zone", "b")
+	a1 := a.Branch()
+	a1.SetName("a1")
+	a1.Branch().SetName("a1x")
+	a.Branch().Prune()
+	localRoot.Branch().SetName("b")
+
+	snap := Snapshot(localRoot)
+	buf, err := snap.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded TreeSnapshot
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snap, decoded) {
+		t.Fatalf("round-trip failed\nexpected %#v\ngot %#v", snap, decoded)
+	}
+	if jsonBuf, _ := json.Marshal(snap); len(buf) >= len(jsonBuf) {
+		t.Fatalf("binary encoding should be smaller than json, %v >= %v", len(buf), len(jsonBuf))
+	}
+
+	for i := 0; i < len(buf); i++ {
+		if err := new(TreeSnapshot).UnmarshalBinary(buf[:i]); err == nil {
+			t.Fatalf("decoding %v of %v bytes should fail", i, len(buf))
+		}
+	}
+	newer := append([]byte{snapshotVersion + 1}, buf[1:]...)
+	if err := new(TreeSnapshot).UnmarshalBinary(newer); !errors.Is(err, ErrSnapshotVersion) {
+		t.Fatalf("expecting ErrSnapshotVersion got %v", err)
+	}
+
+	// fields appended by newer encoders are skipped
+	leaf := TreeSnapshot{PID: 7, Name: "leaf", State: StateDone}
+	buf, _ = leaf.MarshalBinary()
+	body := append(append([]byte(nil), buf[2:]...), 0x2a, 0x2a)
+	extended := append([]byte{snapshotVersion, byte(len(body))}, body...)
+	decoded = TreeSnapshot{}
+	if err := decoded.UnmarshalBinary(extended); err != nil || !reflect.DeepEqual(leaf, decoded) {
+		t.Fatalf("unknown fields should be skipped, got %#v, %v", decoded, err)
+	}
+}
@@ -0,0 +1,66 @@
+package jungle
+
+import "time"
+
+type (
+	// BranchOptions are applied to every branch created by Spawn
+	BranchOptions struct {
+		// Name of the branches, empty by default
+		Name string
+		// Tags set on every branch
+		Tags map[string]string
+		// Restart makes the branches supervised using the given policy,
+		// when nil branches behave like BranchFunc. It is ignored for
+		// branches without a process function, eg.: a Plan with a nil fn
+		Restart *RestartPolicy
+		// MaxLifetime prunes each branch once it has passed since its
+		// creation, zero means no limit
+		MaxLifetime time.Duration
+	}
+)
+
+// Spawn creates one branch of parent for each item, all of them configured
+// with opts, and runs fn with the branch and its item.
+//
+// The branches are returned in the same order as items, so callers can
+// wait for them. If parent was not created by this package every branch
+// is already done with ErrForeignTree and fn is never called.
+func Spawn[T any](parent Tree, items []T, opts BranchOptions, fn func(Tree, T) error) []Tree {
+	p, ok := parent.(*tree)
+	trees := make([]Tree, len(items))
+	for i, item := range items {
+		item := item
+		if !ok {
+			trees[i] = rejectedTree(ErrForeignTree)
+			continue
+		}
+		trees[i] = p.branchWith(opts, func(t Tree) error {
+			return fn(t, item)
+		})
+	}
+	return trees
+}
+
+// branchWith creates a new branch running fn configured by opts
+func (t *tree) branchWith(opts BranchOptions, fn processFunc) Tree {
+	var s *supervisor
+	if opts.Restart != nil && fn != nil {
+		s = &supervisor{policy: *opts.Restart, fn: fn}
+		fn = s.run
+	}
+	branch := newTree(t, fn)
+	branch.supervisor = s
+	branch.lifetime = opts.MaxLifetime
+	// the branch is not visible to anyone yet, but the lock keeps
+	// the race detector happy
+	branch.mu.Lock()
+	branch.name = opts.Name
+	for k, v := range opts.Tags {
+		if branch.tags == nil {
+			branch.tags = make(map[string]string, len(opts.Tags))
+		}
+		branch.tags[k] = v
+	}
+	branch.mu.Unlock()
+	return t.grow(branch)
+}
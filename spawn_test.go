@@ -0,0 +1,90 @@
+package jungle
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSpawn(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+
+	var mu sync.Mutex
+	var got []int
+	trees := Spawn(parent, []int{1, 2, 3}, BranchOptions{
+		Name: "worker",
+		Tags: map[string]string{"pool": "a"},
+	}, func(_ Tree, n int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, n)
+		return nil
+	})
+	if len(trees) != 3 {
+		t.Fatalf("expecting 3 trees got %v", len(trees))
+	}
+	for _, tr := range trees {
+		<-tr.Done()
+		if tr.Name() != "worker" || tr.Tags()["pool"] != "a" {
+			t.Errorf("options not applied, name %q tags %v", tr.Name(), tr.Tags())
+		}
+	}
+	sort.Ints(got)
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("each item should be delivered once, got %v", got)
+	}
+}
+
+func TestSpawnOptions(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+
+	var mu sync.Mutex
+	runs := make(map[string]int)
+	trees := Spawn(parent, []string{"a", "b"}, BranchOptions{
+		Restart: &RestartPolicy{MaxRestarts: 2},
+	}, func(_ Tree, s string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		runs[s]++
+		return errors.New("fail")
+	})
+	for _, tr := range trees {
+		<-tr.Done()
+	}
+	if runs["a"] != 3 || runs["b"] != 3 {
+		t.Fatalf("each item should run once plus two restarts, got %v", runs)
+	}
+
+	trees = Spawn(parent, []int{1}, BranchOptions{MaxLifetime: 10 * time.Millisecond}, func(t Tree, _ int) error {
+		<-t.Pruned()
+		return nil
+	})
+	select {
+	case <-trees[0].Done():
+	case <-time.After(time.Second):
+		t.Fatal("branch should be pruned after its max lifetime")
+	}
+}
+
+func TestSpawnForeignParent(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+
+	trees := Spawn(foreignTree{parent}, []int{1, 2}, BranchOptions{}, func(Tree, int) error {
+		t.Error("fn should not run under a foreign parent")
+		return nil
+	})
+	if len(trees) != 2 {
+		t.Fatalf("expecting 2 trees got %v", len(trees))
+	}
+	for _, tr := range trees {
+		<-tr.Done()
+		if !errors.Is(tr.Cause(), ErrForeignTree) {
+			t.Fatalf("expecting ErrForeignTree got %v", tr.Cause())
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Package sqljungle ties the lifecycle of a *sql.DB to a jungle tree.
+package sqljungle
+
+import (
+	"database/sql"
+
+	"github.com/andrebq/jungle"
+)
+
+// Open opens a database, just like sql.Open, which is closed once t is
+// pruned so its connection pool is never leaked.
+func Open(t jungle.Tree, driver, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	jungle.RegisterCloser(t, db)
+	return db, nil
+}
@@ -0,0 +1,77 @@
+package sqljungle
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrebq/jungle"
+)
+
+type (
+	mockDriver struct {
+		closed chan struct{}
+	}
+
+	mockConn struct {
+		closed chan<- struct{}
+	}
+)
+
+var (
+	mock = &mockDriver{closed: make(chan struct{}, 16)}
+)
+
+func init() {
+	sql.Register("sqljungle-mock", mock)
+}
+
+func (d *mockDriver) Open(string) (driver.Conn, error) {
+	return &mockConn{closed: d.closed}, nil
+}
+
+func (c *mockConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *mockConn) Close() error {
+	select {
+	case c.closed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (c *mockConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestOpen(t *testing.T) {
+	branch := jungle.Root().Branch()
+	db, err := Open(branch, "sqljungle-mock", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	branch.Prune()
+	select {
+	case <-mock.closed:
+	case <-time.After(time.Second):
+		t.Fatal("connections should be closed once the tree is pruned")
+	}
+	if err := db.Ping(); err == nil {
+		t.Fatal("db should be closed")
+	}
+}
+
+func TestOpenUnknownDriver(t *testing.T) {
+	branch := jungle.Root().Branch()
+	defer branch.Prune()
+	if _, err := Open(branch, "sqljungle-missing", ""); err == nil {
+		t.Fatal("expecting an error for an unknown driver")
+	}
+}
@@ -0,0 +1,148 @@
+package jungle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// branchStacks holds the debug information captured when
+	// SetCaptureBranchStacks is enabled
+	branchStacks struct {
+		// created is where the branch was created
+		created []byte
+		// goid of the goroutine running the process function,
+		// zero when it is not running
+		goid atomic.Uint64
+	}
+)
+
+const (
+	// maxCreatedStack bounds the memory used by each branch
+	maxCreatedStack = 4 << 10
+	// maxStackDump bounds the buffer used to read all goroutines
+	maxStackDump = 8 << 20
+)
+
+var (
+	captureStacks atomic.Bool
+)
+
+// SetCaptureBranchStacks makes branches created after the call record
+// where they were created and which goroutine runs their process function,
+// so PruneWatchdog can tell where a stuck branch is blocked.
+//
+// It adds a few KB and a stack capture to each branch, so it is meant for
+// debugging only.
+func SetCaptureBranchStacks(enabled bool) {
+	captureStacks.Store(enabled)
+}
+
+// PruneWatchdog prunes t and waits up to timeout for it to be done. If
+// it isn't done by then, the stacks of the process functions of t and its
+// children which are still running are written to w and false is returned.
+//
+// Only branches created while SetCaptureBranchStacks was enabled are
+// included in the dump.
+func PruneWatchdog(t Tree, timeout time.Duration, w io.Writer) bool {
+	t.Prune()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-t.Done():
+		return true
+	case <-timer.C:
+	}
+	DumpBranchStacks(t, w)
+	return false
+}
+
+// DumpBranchStacks writes the stacks of the process functions of t and its
+// children which are still running to w. Nothing is written for trees
+// which were not created by jungle.
+func DumpBranchStacks(t Tree, w io.Writer) {
+	tt, ok := t.(*tree)
+	if !ok {
+		return
+	}
+	tt.dumpStacks(w, allGoroutines())
+}
+
+// dumpStacks writes the stacks of t and its remaining branches, unlike
+// walk it also visits branches which are being pruned
+func (t *tree) dumpStacks(w io.Writer, goroutines map[uint64][]byte) {
+	if t.stacks != nil {
+		if id := t.stacks.goid.Load(); id != 0 {
+			fmt.Fprintf(w, "branch %v (%v) is still running\n", t.pid, t.Name())
+			fmt.Fprintf(w, "created at:\n%s\n", t.stacks.created)
+			if stack, ok := goroutines[id]; ok {
+				fmt.Fprintf(w, "blocked at:\n%s\n", stack)
+			}
+		}
+	}
+	for _, c := range t.remaining() {
+		c.dumpStacks(w, goroutines)
+	}
+}
+
+func newBranchStacks() *branchStacks {
+	buf := make([]byte, maxCreatedStack)
+	return &branchStacks{created: buf[:runtime.Stack(buf, false)]}
+}
+
+// trackStack records the goroutine running the process function of t,
+// the returned function must be called once the process returns
+func (t *tree) trackStack() func() {
+	if t.stacks == nil {
+		return func() {}
+	}
+	t.stacks.goid.Store(currentGoroutine())
+	return func() { t.stacks.goid.Store(0) }
+}
+
+// currentGoroutine returns the id of the calling goroutine, parsed from
+// the header of its stack trace
+func currentGoroutine() uint64 {
+	var buf [64]byte
+	id, _ := goroutineID(buf[:runtime.Stack(buf[:], false)])
+	return id
+}
+
+// goroutineID parses the "goroutine N [state]:" header of a stack trace
+func goroutineID(stack []byte) (uint64, bool) {
+	stack, ok := bytes.CutPrefix(stack, []byte("goroutine "))
+	if !ok {
+		return 0, false
+	}
+	end := bytes.IndexByte(stack, ' ')
+	if end < 0 {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(string(stack[:end]), 10, 64)
+	return id, err == nil
+}
+
+// allGoroutines returns the stack of every goroutine, by id
+func allGoroutines() map[uint64][]byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxStackDump {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := make(map[uint64][]byte)
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if id, ok := goroutineID(stack); ok {
+			stacks[id] = stack
+		}
+	}
+	return stacks
+}
@@ -0,0 +1,53 @@
+package jungle
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func blockedForever(unblock <-chan Signal) {
+	<-unblock
+}
+
+func TestPruneWatchdog(t *testing.T) {
+	SetCaptureBranchStacks(true)
+	defer SetCaptureBranchStacks(false)
+
+	unblock := make(chan Signal)
+	defer close(unblock)
+	started := make(chan Signal)
+	branch := Root().Branch()
+	branch.SetName("stuck")
+	branch.BranchFunc(func(Tree) error {
+		close(started)
+		blockedForever(unblock)
+		return nil
+	})
+	<-started
+
+	var dump bytes.Buffer
+	if PruneWatchdog(branch, 10*time.Millisecond, &dump) {
+		t.Fatal("the watchdog should fire for a stuck branch")
+	}
+	out := dump.String()
+	if !strings.Contains(out, "blockedForever") || !strings.Contains(out, "TestPruneWatchdog") {
+		t.Fatalf("the dump should show where the branch is blocked and where it was created, got:\n%v", out)
+	}
+
+	clean := Root().Branch()
+	if !PruneWatchdog(clean, time.Second, &dump) {
+		t.Fatal("a branch which finishes in time should not trigger the watchdog")
+	}
+}
+
+func TestDumpBranchStacksForeignTree(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	var dump bytes.Buffer
+	DumpBranchStacks(foreignTree{parent}, &dump)
+	if dump.Len() != 0 {
+		t.Fatalf("nothing should be dumped for a foreign tree, got:\n%v", dump.String())
+	}
+}
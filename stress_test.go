@@ -0,0 +1,128 @@
+package jungle
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stress hammers parent with concurrent branching, completion, queries and
+// a prune in the middle of it all, every branch created must be done
+// shortly after parent is done.
+func stress(t *testing.T, parent Tree) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var created []Tree
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				var b Tree
+				switch i % 3 {
+				case 0:
+					b = parent.Branch()
+				case 1:
+					b = parent.BranchFunc(func(Tree) error { return nil })
+				case 2:
+					b = parent.BranchFunc(func(b Tree) error {
+						b.Branch()
+						<-b.Pruned()
+						return nil
+					})
+				}
+				if i%10 == 0 {
+					b.Prune()
+				}
+				mu.Lock()
+				created = append(created, b)
+				mu.Unlock()
+			}
+		}(g)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			Snapshot(parent)
+			parent.Find(func(Tree) bool { return true })
+			Quiesce(parent, 0, 0)
+		}
+	}()
+	time.Sleep(time.Millisecond)
+	parent.Prune()
+	wg.Wait()
+
+	select {
+	case <-parent.Done():
+	case <-time.After(time.Second * 5):
+		t.Fatal("parent should be done")
+	}
+	for _, b := range created {
+		select {
+		case <-b.Done():
+		case <-time.After(time.Second * 5):
+			t.Fatalf("branch %v should be done", b.PID())
+		}
+	}
+}
+
+func TestStressConcurrentBranchAndPrune(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		stress(t, Root().Branch())
+	}
+}
+
+func TestStressConcurrentBranchAndPruneBuffered(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		stress(t, New(WithControlBuffer(16)))
+	}
+}
+
+// TestBranchDuringPrune races branch creation against the prune signal,
+// every branch must either be rejected without running or be started,
+// pruned and waited on by its parent, never orphaned.
+func TestBranchDuringPrune(t *testing.T) {
+	for round := 0; round < 200; round++ {
+		parent := New(WithControlBuffer(round % 4))
+		start := make(chan Signal)
+		type result struct {
+			branch Tree
+			ran    *atomic.Bool
+		}
+		results := make(chan result, 8)
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ran := &atomic.Bool{}
+				<-start
+				b := parent.BranchFunc(func(b Tree) error {
+					ran.Store(true)
+					<-b.Pruned()
+					return nil
+				})
+				results <- result{b, ran}
+			}()
+		}
+		close(start)
+		parent.Prune()
+		wg.Wait()
+		close(results)
+		<-parent.Done()
+		for r := range results {
+			select {
+			case <-r.branch.Done():
+			default:
+				t.Fatalf("round %v: branch %v outlived its parent", round, r.branch.PID())
+			}
+			rejected := errors.Is(r.branch.Cause(), ErrParentPruned)
+			if rejected == r.ran.Load() {
+				t.Fatalf("round %v: branch %v rejected %v but ran %v", round, r.branch.PID(), rejected, r.ran.Load())
+			}
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package jungle
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+type (
+	// Supervised is a Tree which restarts its process function according
+	// to a RestartPolicy
+	Supervised interface {
+		Tree
+		Restartable
+
+		// BreakerState returns the state of the circuit breaker, trees
+		// without a breaker are always BreakerClosed
+		BreakerState() BreakerState
+
+		// PauseRestarts stops restarting the process function until
+		// ResumeRestarts is called
+		PauseRestarts()
+
+		// ResumeRestarts allows restarts again, if a restart was held
+		// it happens right away
+		ResumeRestarts()
+
+		// RestartHistory returns the most recent restarts, oldest first
+		RestartHistory() []RestartRecord
+
+		// SetSuccessPredicate decides which errors returned by the process
+		// function count as a clean exit
+		SetSuccessPredicate(success func(error) bool)
+	}
+
+	// RestartRecord describes one restart of a supervised branch
+	RestartRecord struct {
+		// Time when the run which caused the restart exited
+		Time time.Time
+		// Err returned by the run, nil for Permanent branches which
+		// exited cleanly
+		Err error
+		// Backoff waited before the restart
+		Backoff time.Duration
+	}
+
+	// RestartKind decides which exits of the process function cause
+	// a restart
+	RestartKind int
+
+	// RestartPolicy controls how a supervised branch is restarted when
+	// its process function returns an error
+	RestartPolicy struct {
+		// Kind decides which exits are restarted, Transient by default
+		Kind RestartKind
+
+		// MaxRestarts is the maximum number of restarts, after that the
+		// branch is pruned with the last error. Zero means no limit.
+		MaxRestarts int
+
+		// MinRuntime is how long a run must last to count as a successful
+		// start. When set, only runs which exit before MinRuntime (fast
+		// crashes) count towards MaxRestarts and a run which lasted at
+		// least MinRuntime resets the count. A crash loop gives up after
+		// MaxRestarts fast crashes in a row, while a branch which fails
+		// once in a while after running for some time is always restarted.
+		MinRuntime time.Duration
+
+		// Backoff is how long to wait before each restart
+		Backoff time.Duration
+
+		// Breaker stops restarts for a while after too many failures,
+		// nil disables it
+		Breaker *BreakerPolicy
+
+		// EscalateOnExhaustion prunes the parent of the supervised branch
+		// once it runs out of restarts. If the parent is itself running
+		// under a supervised branch, that one is restarted.
+		EscalateOnExhaustion bool
+
+		// PanicAsError recovers panics from the process function and
+		// handles them just like an error, wrapped in a *PanicError, so
+		// they count towards MaxRestarts and the breaker
+		PanicAsError bool
+	}
+
+	supervisor struct {
+		policy RestartPolicy
+
+		mu       sync.Mutex
+		fn       processFunc
+		state    BreakerState
+		failures int
+		runStart time.Time
+		// resumed is non-nil while restarts are paused
+		resumed chan Signal
+		success func(error) bool
+		// history is a ring buffer, next is where the next record goes
+		history [restartHistorySize]RestartRecord
+		next    int
+	}
+)
+
+const (
+	// restartHistorySize is how many records RestartHistory keeps
+	restartHistorySize = 32
+)
+
+const (
+	// Transient restarts only when the process function returns an error
+	Transient RestartKind = iota
+	// Permanent always restarts, even if the process function returns nil
+	Permanent
+	// Temporary never restarts, the branch behaves like BranchFunc
+	Temporary
+)
+
+var (
+	// ErrNotSupervised is returned when a supervision method is called
+	// on a branch that wasn't created by BranchSupervised
+	ErrNotSupervised = errors.New("jungle: branch is not supervised")
+
+	// ErrEscalated is the cause used to prune the parent of a supervised
+	// branch which exhausted its restarts with EscalateOnExhaustion
+	ErrEscalated = errors.New("jungle: restarts exhausted")
+)
+
+// BranchSupervised creates a new branch that runs fn, but instead of pruning
+// the branch when fn returns an error, fn is restarted after the policy
+// backoff.
+//
+// Each run receives a fresh child of the supervised branch, which is pruned
+// (along with everything fn created) once fn returns. If that child is pruned
+// with a cause by someone else, eg.: a child escalating its failure, the run
+// is considered a failure even if fn returns nil.
+//
+// A nil error or running out of restarts prunes the branch just like
+// BranchFunc, the policy Kind changes which exits are restarted. Restarts
+// never happen once the branch was pruned.
+func (t *tree) BranchSupervised(fn func(Tree) error, policy RestartPolicy) Supervised {
+	s := &supervisor{policy: policy, fn: fn}
+	branch := newTree(t, s.run)
+	branch.supervisor = s
+	t.grow(branch)
+	return branch
+}
+
+// ReplaceProcess changes the function used by a supervised branch, the
+// new function is used at the next restart without interrupting the
+// current execution.
+func (t *tree) ReplaceProcess(fn func(Tree) error) error {
+	if t.supervisor == nil {
+		return ErrNotSupervised
+	}
+	t.supervisor.mu.Lock()
+	defer t.supervisor.mu.Unlock()
+	t.supervisor.fn = fn
+	return nil
+}
+
+// PauseRestarts makes a supervised branch hold its next restart until
+// ResumeRestarts is called. The current run is not affected, but once it
+// fails the branch stays alive without running anything. It has no effect
+// on trees which are not supervised.
+func (t *tree) PauseRestarts() {
+	if t.supervisor == nil {
+		return
+	}
+	s := t.supervisor
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.resumed == nil {
+		s.resumed = make(chan Signal)
+	}
+}
+
+// ResumeRestarts undoes PauseRestarts, a restart which was held happens
+// right away.
+func (t *tree) ResumeRestarts() {
+	if t.supervisor == nil {
+		return
+	}
+	s := t.supervisor
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.resumed != nil {
+		close(s.resumed)
+		s.resumed = nil
+	}
+}
+
+// SetSuccessPredicate makes a supervised branch treat every error for which
+// success returns true as a clean exit, just like a nil error: under the
+// Transient kind the branch is not restarted and it is pruned without
+// error, eg.: for an ErrGracefulStop returned by a worker asked to stop.
+//
+// success is called with every non-nil error returned by the process
+// function, starting with the next exit. A nil predicate restores the
+// default, where only nil is a clean exit. It has no effect on trees which
+// are not supervised.
+func (t *tree) SetSuccessPredicate(success func(error) bool) {
+	if t.supervisor == nil {
+		return
+	}
+	s := t.supervisor
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.success = success
+}
+
+// clean returns nil if err counts as a clean exit
+func (s *supervisor) clean(err error) error {
+	if err == nil {
+		return nil
+	}
+	s.mu.Lock()
+	success := s.success
+	s.mu.Unlock()
+	if success != nil && success(err) {
+		return nil
+	}
+	return err
+}
+
+// restartsPaused returns a channel closed once restarts are resumed, or nil
+// if they are not paused
+func (s *supervisor) restartsPaused() chan Signal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resumed
+}
+
+// RestartHistory returns the last restarts of a supervised branch, oldest
+// first, so operators can tell when and why a flapping branch restarted.
+// Only the 32 most recent restarts are kept. Trees which are not supervised
+// have no history.
+func (t *tree) RestartHistory() []RestartRecord {
+	if t.supervisor == nil {
+		return nil
+	}
+	s := t.supervisor
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var history []RestartRecord
+	for i := 0; i < restartHistorySize; i++ {
+		r := s.history[(s.next+i)%restartHistorySize]
+		if !r.Time.IsZero() {
+			history = append(history, r)
+		}
+	}
+	return history
+}
+
+// record adds r to the restart history, replacing the oldest record
+func (s *supervisor) record(r RestartRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history[s.next] = r
+	s.next = (s.next + 1) % restartHistorySize
+}
+
+func (s *supervisor) process() processFunc {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fn
+}
+
+// run is the process function of a supervised tree
+func (s *supervisor) run(t Tree) error {
+	branch := t.(*tree)
+	var restarts int
+	for {
+		s.started()
+		start := time.Now()
+		err := s.clean(s.attempt(branch))
+		if !s.policy.Kind.restarts(err) || !branch.alive() {
+			return err
+		}
+		if s.policy.MinRuntime > 0 && time.Since(start) >= s.policy.MinRuntime {
+			restarts = 0
+		}
+		if s.policy.MaxRestarts > 0 && restarts >= s.policy.MaxRestarts {
+			if s.policy.EscalateOnExhaustion && err != nil && branch.parent != nil {
+				branch.parent.PruneWith(fmt.Errorf("%w: %w", ErrEscalated, err))
+			}
+			return err
+		}
+		restarts++
+		wait := s.policy.Backoff
+		if err != nil {
+			wait = s.failed()
+		}
+		s.record(RestartRecord{Time: time.Now(), Err: err, Backoff: wait})
+		select {
+		case <-time.After(wait):
+		case <-branch.prune:
+			return err
+		}
+		if resumed := s.restartsPaused(); resumed != nil {
+			select {
+			case <-resumed:
+			case <-branch.prune:
+				return err
+			}
+		}
+		s.retry()
+	}
+}
+
+// call runs the process function, recovering panics if the policy
+// treats them as errors
+func (s *supervisor) call(run Tree) (err error) {
+	if s.policy.PanicAsError {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+			}
+		}()
+	}
+	return s.process()(run)
+}
+
+// restarts returns true if a run which returned err should be restarted
+func (k RestartKind) restarts(err error) bool {
+	switch k {
+	case Permanent:
+		return true
+	case Temporary:
+		return false
+	default:
+		return err != nil
+	}
+}
+
+func (k RestartKind) String() string {
+	switch k {
+	case Transient:
+		return "transient"
+	case Permanent:
+		return "permanent"
+	case Temporary:
+		return "temporary"
+	default:
+		return "unknown"
+	}
+}
+
+// attempt runs the process function once, on a new child of branch,
+// and waits until that child is done
+func (s *supervisor) attempt(branch *tree) error {
+	run := branch.attach(newTree(branch, nil))
+	err := s.call(run)
+	if cause := run.Cause(); err == nil && cause != nil && branch.alive() {
+		// pruned with a cause by someone else
+		err = cause
+	}
+	run.Prune()
+	<-run.Done()
+	return err
+}
@@ -0,0 +1,291 @@
+package jungle
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBranchSupervised(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	var runs int32
+	branch := localRoot.BranchSupervised(func(Tree) error {
+		atomic.AddInt32(&runs, 1)
+		return errors.New("failed")
+	}, RestartPolicy{MaxRestarts: 2})
+	select {
+	case <-branch.Done():
+	case <-time.After(time.Second):
+		t.Fatal("branch should be done after exhausting its restarts")
+	}
+	if atomic.LoadInt32(&runs) != 3 {
+		t.Fatalf("should run 3 times got %v", atomic.LoadInt32(&runs))
+	}
+}
+
+func TestReplaceProcess(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	restart := make(chan Signal)
+	replaced := make(chan Signal)
+	branch := localRoot.BranchSupervised(func(Tree) error {
+		<-restart
+		return errors.New("restart")
+	}, RestartPolicy{})
+	if err := branch.ReplaceProcess(func(b Tree) error {
+		close(replaced)
+		<-b.Pruned()
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	close(restart)
+	select {
+	case <-replaced:
+	case <-time.After(time.Second):
+		t.Fatal("the new process should run after the restart")
+	}
+
+	if err := localRoot.(Supervised).ReplaceProcess(nil); err != ErrNotSupervised {
+		t.Fatalf("expecting ErrNotSupervised got %v", err)
+	}
+}
+
+func TestEscalateOnExhaustion(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	var parentRuns int32
+	childPolicy := RestartPolicy{MaxRestarts: 1, EscalateOnExhaustion: true}
+	parent := localRoot.BranchSupervised(func(b Tree) error {
+		if atomic.AddInt32(&parentRuns, 1) == 1 {
+			b.BranchSupervised(func(Tree) error {
+				return errors.New("child failed")
+			}, childPolicy)
+		}
+		<-b.Pruned()
+		return nil
+	}, RestartPolicy{})
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&parentRuns) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("parent should be restarted after the child escalates")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if isClosed(parent.Pruned()) {
+		t.Fatal("the supervised parent should be restarted, not pruned")
+	}
+
+	// without a supervisor, escalation prunes the parent
+	plain := localRoot.Branch()
+	plain.BranchSupervised(func(Tree) error {
+		return errors.New("child failed")
+	}, childPolicy)
+	select {
+	case <-plain.Done():
+	case <-time.After(time.Second):
+		t.Fatal("parent should be pruned after the child escalates")
+	}
+	if !errors.Is(plain.Cause(), ErrEscalated) {
+		t.Fatalf("expecting ErrEscalated got %v", plain.Cause())
+	}
+}
+
+func TestRestartKind(t *testing.T) {
+	for _, tc := range []struct {
+		kind RestartKind
+		err  error
+		runs int32
+	}{
+		{Transient, nil, 1},
+		{Transient, errors.New("fail"), 3},
+		{Permanent, nil, 3},
+		{Permanent, errors.New("fail"), 3},
+		{Temporary, nil, 1},
+		{Temporary, errors.New("fail"), 1},
+	} {
+		localRoot := Root().Branch()
+		var runs int32
+		branch := localRoot.BranchSupervised(func(Tree) error {
+			atomic.AddInt32(&runs, 1)
+			return tc.err
+		}, RestartPolicy{Kind: tc.kind, MaxRestarts: 2})
+		select {
+		case <-branch.Done():
+		case <-time.After(time.Second):
+			t.Fatalf("%v: branch should be done", tc.kind)
+		}
+		if got := atomic.LoadInt32(&runs); got != tc.runs {
+			t.Errorf("%v with error %v: expecting %v runs got %v", tc.kind, tc.err, tc.runs, got)
+		}
+		if !errors.Is(branch.Err(), tc.err) {
+			t.Errorf("%v: expecting error %v got %v", tc.kind, tc.err, branch.Err())
+		}
+		localRoot.Prune()
+	}
+}
+
+func TestPanicAsError(t *testing.T) {
+	localRoot := Root().Branch()
+	var runs int32
+	localRoot.BranchSupervised(func(Tree) error {
+		atomic.AddInt32(&runs, 1)
+		panic("boom")
+	}, RestartPolicy{MaxRestarts: 2, PanicAsError: true, EscalateOnExhaustion: true})
+	select {
+	case <-localRoot.Done():
+	case <-time.After(time.Second):
+		t.Fatal("parent should be pruned after the restarts are exhausted")
+	}
+	if got := atomic.LoadInt32(&runs); got != 3 {
+		t.Fatalf("panics should count as failures, expecting 3 runs got %v", got)
+	}
+	var perr *PanicError
+	if !errors.Is(localRoot.Cause(), ErrEscalated) || !errors.As(localRoot.Cause(), &perr) || perr.Value != "boom" {
+		t.Fatalf("expecting an escalated panic got %v", localRoot.Cause())
+	}
+}
+
+func TestPauseRestarts(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	runs := make(chan Signal, 10)
+	fail := make(chan Signal)
+	branch := localRoot.BranchSupervised(func(Tree) error {
+		runs <- Signal{}
+		<-fail
+		return errors.New("dependency down")
+	}, RestartPolicy{})
+	<-runs
+	branch.PauseRestarts()
+	fail <- Signal{}
+	select {
+	case <-runs:
+		t.Fatal("no restart should happen while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if branch.IsPruned() {
+		t.Fatal("the branch should stay alive while paused")
+	}
+	branch.ResumeRestarts()
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("the branch should restart after resume")
+	}
+}
+
+func TestMinRuntime(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	policy := RestartPolicy{MaxRestarts: 2, MinRuntime: 20 * time.Millisecond}
+
+	var fastRuns int32
+	fast := localRoot.BranchSupervised(func(Tree) error {
+		atomic.AddInt32(&fastRuns, 1)
+		return errors.New("crashed on start")
+	}, policy)
+	select {
+	case <-fast.Done():
+	case <-time.After(time.Second):
+		t.Fatal("a crash loop should give up after MaxRestarts fast crashes")
+	}
+	if runs := atomic.LoadInt32(&fastRuns); runs != 3 {
+		t.Fatalf("should run 3 times got %v", runs)
+	}
+
+	slowRuns := make(chan Signal, 10)
+	slow := localRoot.BranchSupervised(func(Tree) error {
+		time.Sleep(policy.MinRuntime)
+		slowRuns <- Signal{}
+		return errors.New("failed after a while")
+	}, policy)
+	for i := 0; i < 5; i++ {
+		<-slowRuns
+	}
+	if slow.IsPruned() {
+		t.Fatal("runs longer than MinRuntime should not count towards MaxRestarts")
+	}
+}
+
+func TestRestartHistory(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	var runs int32
+	branch := localRoot.BranchSupervised(func(Tree) error {
+		return fmt.Errorf("run %v", atomic.AddInt32(&runs, 1))
+	}, RestartPolicy{MaxRestarts: 3, Backoff: time.Millisecond})
+	<-branch.Done()
+
+	history := branch.RestartHistory()
+	if len(history) != 3 {
+		t.Fatalf("expecting 3 records got %v", history)
+	}
+	for i, r := range history {
+		if expected := fmt.Sprintf("run %v", i+1); r.Err == nil || r.Err.Error() != expected {
+			t.Fatalf("record %v: expecting %v got %v", i, expected, r.Err)
+		}
+		if r.Backoff != time.Millisecond {
+			t.Fatalf("record %v: expecting a backoff of 1ms got %v", i, r.Backoff)
+		}
+		if i > 0 && r.Time.Before(history[i-1].Time) {
+			t.Fatalf("records should be ordered by time, got %v", history)
+		}
+	}
+
+	if h := localRoot.Branch().(Supervised).RestartHistory(); h != nil {
+		t.Fatalf("trees which are not supervised have no history, got %v", h)
+	}
+}
+
+func TestRestartHistoryBounded(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	var runs int32
+	branch := localRoot.BranchSupervised(func(Tree) error {
+		return fmt.Errorf("run %v", atomic.AddInt32(&runs, 1))
+	}, RestartPolicy{MaxRestarts: restartHistorySize + 10})
+	<-branch.Done()
+
+	history := branch.RestartHistory()
+	if len(history) != restartHistorySize {
+		t.Fatalf("expecting %v records got %v", restartHistorySize, len(history))
+	}
+	if first := history[0].Err.Error(); first != "run 11" {
+		t.Fatalf("only the most recent records should be kept, first is %v", first)
+	}
+}
+
+func TestSetSuccessPredicate(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	errGracefulStop := errors.New("graceful stop")
+	exits := make(chan error)
+	var runs int32
+	branch := localRoot.BranchSupervised(func(Tree) error {
+		atomic.AddInt32(&runs, 1)
+		return <-exits
+	}, RestartPolicy{})
+	branch.SetSuccessPredicate(func(err error) bool {
+		return errors.Is(err, errGracefulStop)
+	})
+
+	exits <- errors.New("crashed")
+	exits <- fmt.Errorf("worker: %w", errGracefulStop)
+	select {
+	case <-branch.Done():
+	case <-time.After(time.Second):
+		t.Fatal("a successful exit should not be restarted")
+	}
+	if r := atomic.LoadInt32(&runs); r != 2 {
+		t.Fatalf("only the failure should restart, expecting 2 runs got %v", r)
+	}
+	if err := branch.Err(); err != nil {
+		t.Fatalf("a successful exit should not be an error, got %v", err)
+	}
+}
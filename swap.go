@@ -0,0 +1,63 @@
+package jungle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultSwapTimeout is how long Swap waits for the new branch to
+	// become healthy
+	DefaultSwapTimeout = 30 * time.Second
+)
+
+var (
+	// ErrNotHealthy is returned by Swap when the new branch doesn't become
+	// healthy in time, it is also the cause used to prune it
+	ErrNotHealthy = errors.New("jungle: branch did not become healthy")
+
+	// ErrNoParent is returned when an operation needs the parent of a root
+	ErrNoParent = errors.New("jungle: tree has no parent")
+)
+
+// Swap replaces old by a new sibling running newFn, waiting up to
+// DefaultSwapTimeout for it to become healthy. See SwapWithin.
+func Swap(old Tree, newFn func(Tree) error) (Tree, error) {
+	return SwapWithin(old, newFn, DefaultSwapTimeout)
+}
+
+// SwapWithin starts a new sibling of old running newFn and waits for it to
+// call MarkHealthy, only then old is pruned and the new branch is returned.
+//
+// If the new branch is done or doesn't become healthy within timeout, it
+// is pruned and old is kept running and returned with ErrNotHealthy.
+// Trees which were not created by jungle can't be swapped, old is returned
+// with ErrForeignTree.
+func SwapWithin(old Tree, newFn func(Tree) error, timeout time.Duration) (Tree, error) {
+	tt, ok := old.(*tree)
+	if !ok {
+		return old, ErrForeignTree
+	}
+	parent := tt.parent
+	if parent == nil {
+		return old, ErrNoParent
+	}
+	next := parent.BranchFunc(newFn)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-Healthy(next):
+		old.Prune()
+		return next, nil
+	case <-next.Done():
+		if err := next.Err(); err != nil {
+			return old, fmt.Errorf("%w: %w", ErrNotHealthy, err)
+		}
+		return old, ErrNotHealthy
+	case <-timer.C:
+		next.PruneWith(ErrNotHealthy)
+		<-next.Done()
+		return old, ErrNotHealthy
+	}
+}
@@ -0,0 +1,74 @@
+package jungle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSwap(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	old := parent.BranchFunc(func(t Tree) error {
+		MarkHealthy(t)
+		<-t.Pruned()
+		return nil
+	})
+	<-Healthy(old)
+
+	next, err := Swap(old, func(t Tree) error {
+		MarkHealthy(t)
+		<-t.Pruned()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-old.Done():
+	case <-time.After(time.Second):
+		t.Fatal("old branch should be pruned after the swap")
+	}
+	if next.IsPruned() {
+		t.Fatal("new branch should keep running")
+	}
+}
+
+func TestSwapRollback(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	old := parent.Branch()
+
+	next, err := SwapWithin(old, func(t Tree) error {
+		<-t.Pruned()
+		return nil
+	}, 10*time.Millisecond)
+	if !errors.Is(err, ErrNotHealthy) || next != old {
+		t.Fatalf("expecting ErrNotHealthy and the old branch got %v", err)
+	}
+	failure := errors.New("bad config")
+	_, err = Swap(old, func(Tree) error { return failure })
+	if !errors.Is(err, ErrNotHealthy) || !errors.Is(err, failure) {
+		t.Fatalf("expecting the error of the new branch got %v", err)
+	}
+	if old.IsPruned() {
+		t.Fatal("old branch should keep running after a failed swap")
+	}
+	if _, err := Swap(Root(), func(Tree) error { return nil }); !errors.Is(err, ErrNoParent) {
+		t.Fatalf("expecting ErrNoParent got %v", err)
+	}
+}
+
+func TestSwapForeignTree(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	old := foreignTree{parent.Branch()}
+	next, err := Swap(old, func(Tree) error { return nil })
+	if !errors.Is(err, ErrForeignTree) || next != old {
+		t.Fatalf("expecting ErrForeignTree and the old branch got %v", err)
+	}
+	MarkHealthy(old)
+	if Healthy(old) != nil {
+		t.Fatal("a foreign tree can't be observed")
+	}
+}
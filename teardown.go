@@ -0,0 +1,36 @@
+package jungle
+
+import (
+	"errors"
+	"log/slog"
+)
+
+var (
+	// ErrTeardownTimeout is the Cause of the Abandoned events logged for
+	// branches left behind after the teardown timeout
+	ErrTeardownTimeout = errors.New("jungle: teardown timeout, branch abandoned")
+)
+
+// abandon gives up waiting for branches after the teardown timeout, any
+// branch still in the buffer is rejected since it will never be started.
+//
+// The stuck branches are reported to the Logger, or with slog at warn
+// level if no Logger was set, so they are never left behind silently.
+func (t *tree) abandon(branches subtrees) {
+	t.seal()
+	for len(t.newBranch) > 0 {
+		(<-t.newBranch).reject(ErrParentPruned)
+	}
+	l := currentLogger()
+	if _, none := l.(nopLogger); none {
+		stuck := make([]uint64, 0, len(branches))
+		for _, c := range branches {
+			stuck = append(stuck, c.pid)
+		}
+		slog.Warn("jungle: teardown timeout, abandoning branches", "pid", t.pid, "name", t.Name(), "stuck", stuck)
+		return
+	}
+	for _, c := range branches {
+		l.LogEvent(c.pid, c.Name(), Event{Kind: Abandoned, PID: c.pid, Cause: ErrTeardownTimeout, Tags: c.Tags()})
+	}
+}
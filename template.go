@@ -0,0 +1,41 @@
+package jungle
+
+type (
+	// Template is a branch configuration and process function which can
+	// be instantiated under any parent, many times.
+	//
+	// Templates are immutable and safe for concurrent use.
+	Template struct {
+		opts BranchOptions
+		fn   processFunc
+	}
+)
+
+// NewTemplate returns a Template that creates branches configured by opts
+// running fn. opts is copied, changing it afterwards doesn't affect the
+// template.
+func NewTemplate(opts BranchOptions, fn func(Tree) error) *Template {
+	if opts.Tags != nil {
+		tags := make(map[string]string, len(opts.Tags))
+		for k, v := range opts.Tags {
+			tags[k] = v
+		}
+		opts.Tags = tags
+	}
+	if opts.Restart != nil {
+		policy := *opts.Restart
+		opts.Restart = &policy
+	}
+	return &Template{opts: opts, fn: fn}
+}
+
+// Branch creates a new branch of parent from the template, if parent was
+// not created by this package the branch is already done with
+// ErrForeignTree.
+func (tmpl *Template) Branch(parent Tree) Tree {
+	p, ok := parent.(*tree)
+	if !ok {
+		return rejectedTree(ErrForeignTree)
+	}
+	return p.branchWith(tmpl.opts, tmpl.fn)
+}
@@ -0,0 +1,45 @@
+package jungle
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	var runs int32
+	opts := BranchOptions{Name: "cache", Tags: map[string]string{"tier": "l1"}}
+	tmpl := NewTemplate(opts, func(Tree) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	opts.Tags["tier"] = "changed"
+
+	a, b := Root().Branch(), Root().Branch()
+	defer a.Prune()
+	defer b.Prune()
+	for _, parent := range []Tree{a, b, a} {
+		branch := tmpl.Branch(parent)
+		<-branch.Done()
+		if branch.Name() != "cache" || branch.Tags()["tier"] != "l1" {
+			t.Fatalf("template options not applied, name %q tags %v", branch.Name(), branch.Tags())
+		}
+	}
+	if atomic.LoadInt32(&runs) != 3 {
+		t.Fatalf("expecting 3 runs got %v", atomic.LoadInt32(&runs))
+	}
+}
+
+func TestTemplateForeignParent(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	tmpl := NewTemplate(BranchOptions{}, func(Tree) error {
+		t.Error("fn should not run under a foreign parent")
+		return nil
+	})
+	branch := tmpl.Branch(foreignTree{parent})
+	<-branch.Done()
+	if !errors.Is(branch.Cause(), ErrForeignTree) {
+		t.Fatalf("expecting ErrForeignTree got %v", branch.Cause())
+	}
+}
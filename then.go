@@ -0,0 +1,24 @@
+package jungle
+
+// Then creates a branch of parent which runs first and, once first and all
+// of its children are done, runs second. Each phase runs on its own child
+// of the returned branch.
+//
+// If first returns an error, second never runs and the branch is pruned
+// with that error, just like BranchFunc. Pruning the branch stops the
+// current phase and skips the next one.
+func Then(parent Tree, first, second func(Tree) error) Tree {
+	return parent.BranchFunc(func(seq Tree) error {
+		for _, fn := range []func(Tree) error{first, second} {
+			phase := seq.BranchFunc(fn)
+			<-phase.Done()
+			if err := phase.Err(); err != nil {
+				return err
+			}
+			if seq.IsPruned() {
+				return nil
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,52 @@
+package jungle
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThen(t *testing.T) {
+	var firstDone, childDone atomic.Bool
+	secondStarted := make(chan bool, 1)
+	seq := Then(Root(), func(t Tree) error {
+		t.BranchFunc(func(Tree) error {
+			time.Sleep(20 * time.Millisecond)
+			childDone.Store(true)
+			return nil
+		})
+		firstDone.Store(true)
+		return nil
+	}, func(Tree) error {
+		secondStarted <- firstDone.Load() && childDone.Load()
+		return nil
+	})
+	<-seq.Done()
+	select {
+	case ok := <-secondStarted:
+		if !ok {
+			t.Fatal("second should start only after first and its children are done")
+		}
+	default:
+		t.Fatal("second should run")
+	}
+}
+
+func TestThenError(t *testing.T) {
+	failure := errors.New("migration failed")
+	var secondRan atomic.Bool
+	seq := Then(Root(), func(Tree) error {
+		return failure
+	}, func(Tree) error {
+		secondRan.Store(true)
+		return nil
+	})
+	<-seq.Done()
+	if !errors.Is(seq.Err(), failure) {
+		t.Fatalf("expecting the error from first got %v", seq.Err())
+	}
+	if secondRan.Load() {
+		t.Fatal("second should not run after first fails")
+	}
+}
@@ -1,9 +1,21 @@
 package jungle
 
-import "sync/atomic"
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 type (
 	// Tree is the starting point of a process tree
+	//
+	// Tree holds what every tree supports: creating, pruning and waiting
+	// for branches, their identity and traversal. Features that only some
+	// trees have are narrow interfaces reached with the As* helpers, eg.:
+	// AsDeadlined, and monitoring extras are package functions taking a
+	// Tree, so other implementations of Tree stay small.
 	Tree interface {
 		// Branch a new tree from this one
 		Branch() Tree
@@ -19,9 +31,189 @@ type (
 		// Done/Prune/Pruned/Branch/BranchFunc are safe to call, without
 		// risking a deadlock.
 		BranchFunc(func(Tree) error) Tree
+
+		// BranchService is like BranchFunc but the branch is only pruned
+		// when the function returns a non-nil error. A nil error means the
+		// service finished its setup and should stay alive until pruned.
+		BranchService(func(Tree) error) Tree
+
+		// BranchFuncCtx is like BranchFunc but fn also receives a context
+		// which is canceled when the branch is pruned
+		BranchFuncCtx(func(context.Context, Tree) error) Tree
+
+		// BranchContext creates a new branch whose contexts, and the ones
+		// of its descendants, carry the values of ctx
+		BranchContext(ctx context.Context) Tree
+
+		// BranchDeadline creates a new branch which is pruned automatically
+		// once the deadline is reached
+		BranchDeadline(deadline time.Time) Tree
+
+		// WithCancelCause returns a context canceled when this tree is
+		// pruned, calling cancel prunes the tree with the given cause
+		WithCancelCause() (context.Context, context.CancelCauseFunc)
+
+		// BranchMaxLifetime creates a new branch which is pruned
+		// automatically after d, regardless of what it is doing
+		BranchMaxLifetime(d time.Duration) Tree
+
+		// BranchChildTimeout creates a new branch running fn whose
+		// children are pruned after d, while fn keeps running
+		BranchChildTimeout(fn func(Tree) error, d time.Duration) Tree
+
+		// BranchFuncTimeout creates a new branch running fn which is
+		// pruned if fn doesn't return within d after it starts
+		BranchFuncTimeout(fn func(Tree) error, d time.Duration) Tree
+
+		// BranchSupervised creates a new branch that runs fn and restarts it
+		// according to the given policy
+		BranchSupervised(fn func(Tree) error, policy RestartPolicy) Supervised
+
+		// BranchRetry creates a new branch that runs fn until it succeeds,
+		// up to attempts times
+		BranchRetry(fn func(Tree) error, attempts int, backoff Backoff) Tree
+
+		// RunOn creates a new branch whose process function is executed by
+		// the given runner instead of a dedicated goroutine
+		RunOn(r *Runner, fn func(Tree) error) Tree
+
 		Pruned() <-chan Signal
 		Done() <-chan struct{}
 		Prune()
+
+		// IsPruned is a cheap alternative to Pruned for polling, it
+		// returns true once the tree received the prune signal
+		IsPruned() bool
+
+		// PruneWith is like Prune but records why the tree was pruned
+		PruneWith(cause error)
+
+		// Cause returns the error given to PruneWith, or the error returned
+		// by the process function, nil if the tree wasn't pruned
+		Cause() error
+
+		// Events returns a channel that receives the lifecycle events of
+		// this tree, it is closed after the tree is done
+		Events() <-chan Event
+
+		// AddListener registers fn to be called with the lifecycle events
+		// of this tree, the returned id is used to remove it later
+		AddListener(fn func(Event)) ListenerID
+
+		// RemoveListener stops calling the listener with the given id
+		RemoveListener(id ListenerID)
+
+		// Pause makes this tree refuse new branches until Resume is called
+		Pause()
+
+		// Resume allows new branches after a Pause
+		Resume()
+
+		// Paused returns a channel which is closed while the tree is paused
+		Paused() <-chan Signal
+
+		// PruneChildren prunes all the direct children of this tree but
+		// keeps the tree itself alive, so new branches can be created later
+		PruneChildren()
+
+		// PruneChildrenWait works like PruneChildren but only returns after
+		// all the pruned children are done
+		PruneChildrenWait()
+
+		// PruneCollect prunes this tree, waits until it is done and returns
+		// the errors of the pruned branches, see Err
+		PruneCollect() []error
+
+		// Drain stops accepting new branches, waits for the current ones
+		// to finish on their own and then prunes this tree
+		Drain()
+
+		// DrainWithProgress works like Drain, calling fn with the number
+		// of branches still running each time one finishes
+		DrainWithProgress(fn func(remaining int))
+
+		// PruneProgress returns a channel with the fraction of children
+		// that completed since the prune started
+		PruneProgress() <-chan float64
+
+		// Err returns the error from the process function, once the tree
+		// is done
+		Err() error
+
+		// TimedOut returns true if the tree was pruned by its deadline
+		TimedOut() bool
+
+		// Defer registers fn to run after this tree is done
+		Defer(fn func())
+
+		// AfterFunc runs fn in its own goroutine once this tree is pruned,
+		// unless stop is called before that
+		AfterFunc(fn func()) (stop func() bool)
+
+		// TryBranch works like Branch but returns an error instead of a
+		// done branch when the branch can't be created
+		TryBranch() (Tree, error)
+
+		// TryBranchFunc works like BranchFunc but returns an error instead
+		// of a done branch when the branch can't be created
+		TryBranchFunc(fn func(Tree) error) (Tree, error)
+
+		// SetMaxBranches limits how many branches can be alive under the
+		// root of this tree
+		SetMaxBranches(n int)
+
+		// BranchPreempt works like Branch but when the root is at its
+		// limit, a preemptible child with a lower priority is pruned to
+		// make room
+		BranchPreempt(priority int) Tree
+
+		// LastPruneDuration returns how long the last prune of the root
+		// of this tree took, from the prune signal until it was done
+		LastPruneDuration() time.Duration
+
+		// IsRoot returns true only for the root of a tree (aka the tree
+		// without a parent)
+		IsRoot() bool
+
+		// PID returns the unique identifier of this tree
+		PID() uint64
+
+		// Walk visits this tree and all of its live descendants (depth-first)
+		// until fn returns false
+		Walk(fn func(Tree) bool)
+
+		// FindByPID searches this tree and its live descendants for the
+		// branch with the given pid
+		FindByPID(pid uint64) (Tree, bool)
+
+		// FindByName returns this tree and all live descendants with the
+		// given name, names are not unique
+		FindByName(name string) []Tree
+
+		// Named gives access to the label of this tree
+		Named
+
+		// SetTag attaches metadata to this tree
+		SetTag(key, value string)
+
+		// Tags returns a copy of the metadata attached to this tree
+		Tags() map[string]string
+
+		// FindByTag returns this tree and all live descendants where the
+		// tag key has the given value
+		FindByTag(key, value string) []Tree
+
+		// Find returns this tree and all live descendants matching pred
+		Find(pred func(Tree) bool) []Tree
+
+		// PruneWhere prunes this tree and all live descendants matching
+		// pred, returning how many were pruned
+		PruneWhere(pred func(Tree) bool) int
+
+		// DependsOn declares that this tree uses other, a sibling, so
+		// when their parent is pruned this tree is pruned (and done)
+		// before other is pruned.
+		DependsOn(other Tree) error
 	}
 
 	// Signal is just an alias to an empty struct
@@ -31,36 +223,165 @@ type (
 
 	tree struct {
 		pid        uint64
+		pids       *atomic.Uint64
+		stacks     *branchStacks
+		created    time.Time
 		parent     *tree
+		root       *tree
+		config     *rootConfig
 		prune      chan Signal
-		startPrune chan Signal
 		done       chan struct{}
 		process    chan processFunc
 		newBranch  chan *tree
+		inspect    chan func(subtrees)
+		keepAlive  bool
+		deadline   time.Time
+		lifetime   time.Duration
+		supervisor *supervisor
+		runner     *Runner
+
+		// cause is written by the lifecycle before closing prune
+		cause error
+		// err is written by the process function before the tree is done
+		err error
+
+		// pruned mirrors the prune channel for cheap polling, it is
+		// always set before the channel is closed
+		pruned atomic.Bool
+		// pruneOnce guards cause, pruned and the close of prune
+		pruneOnce sync.Once
+
+		// growMu and sealed control when new branches can be
+		// sent to the lifecycle
+		growMu sync.RWMutex
+		sealed bool
+
+		// activity is the last time (unix nano) a branch was created or
+		// completed anywhere in this subtree, see Quiesce
+		activity atomic.Int64
+
+		// mu protects the metadata below, which is not
+		// managed by the lifecycle
+		mu        sync.Mutex
+		name      string
+		tags      map[string]string
+		events    chan Event
+		eventsLog []Event
+		paused    bool
+		pausedCh  chan Signal
+		values    map[interface{}]interface{}
+		deferred  []func()
+		listeners listeners
+		finalized bool
+
+		// deps are the siblings pruned after this tree, guarded by
+		// depsMu instead of mu since cycle detection walks many trees
+		deps []*tree
+
+		// rejected is only touched by the goroutine creating the branch
+		rejected bool
+		// counted branches are included in the live count of the root
+		counted bool
+		// live and maxBranches are only used on roots
+		live        atomic.Int64
+		maxBranches atomic.Int64
+		// only branches created by BranchPreempt can be preempted,
+		// preempted is set by the call which takes over the slot
+		preemptible bool
+		priority    int
+		preempted   atomic.Bool
+		progress    progress
+		healthy     chan Signal
+		isHealthy   bool
+		healthCheck HealthChecker
+		leaf        chan struct{}
+		memory      func() uint64
+		attempts    atomic.Int32
+		lazy        *lazyStart
+		// pruneStart is only touched by the lifecycle, lastPrune is only
+		// used on roots
+		pruneStart time.Time
+		lastPrune  atomic.Int64
+		// ctxValues is the context whose values are seen by the contexts
+		// derived from this tree, inherited by all branches
+		ctxValues context.Context
+		// goroutines started for this tree, and for the whole root
+		// when t is a root
+		goroutines     atomic.Int32
+		rootGoroutines atomic.Int64
 	}
 
 	subtrees []*tree
 )
 
 var (
+	// ErrParentPruned is the cause of branches created after their
+	// parent was pruned
+	ErrParentPruned = errors.New("jungle: parent was pruned")
+
+	// ErrCrossRoot is returned when an operation mixes trees which
+	// belong to different roots
+	ErrCrossRoot = errors.New("jungle: trees belong to different roots")
+
+	// ErrForeignTree is the cause of branches, or the error returned, when
+	// a Tree which was not created by this package is used where a jungle
+	// tree is required
+	ErrForeignTree = errors.New("jungle: tree was not created by jungle")
+
 	rootTree *tree
 	pid      uint64
 )
 
 func init() {
-	rootTree = newTree(nil, nil)
-	go rootTree.lifecycle()
+	rootTree = startRoot(defaultConfig)
+}
+
+// startRoot creates a root and returns once its lifecycle is running
+func startRoot(config *rootConfig) *tree {
+	root := newRoot(config)
+	go root.lifecycle()
+	// a round-trip to the lifecycle guarantees it is running
+	root.children()
+	return root
 }
 
+func newRoot(config *rootConfig) *tree {
+	root := newTreeWith(nil, config, nil)
+	if sequentialPIDs.Load() {
+		root.pids = new(atomic.Uint64)
+		root.pid = root.pids.Add(1)
+	}
+	return root
+}
+
+// newTree creates a branch of parent sharing the config of its root, or a
+// tree without parent using defaultConfig
 func newTree(parent *tree, fn processFunc) *tree {
-	atomic.AddUint64(&pid, 1)
+	config := defaultConfig
+	if parent != nil {
+		config = parent.config
+	}
+	return newTreeWith(parent, config, fn)
+}
+
+func newTreeWith(parent *tree, config *rootConfig, fn processFunc) *tree {
 	branch := &tree{
-		pid:        atomic.LoadUint64(&pid),
-		parent:     parent,
-		done:       make(chan struct{}),
-		prune:      make(chan Signal),
-		newBranch:  make(chan *tree),
-		startPrune: make(chan Signal),
+		pid:       nextPID(parent),
+		created:   time.Now(),
+		parent:    parent,
+		config:    config,
+		done:      make(chan struct{}),
+		prune:     make(chan Signal),
+		newBranch: make(chan *tree, config.controlBuffer),
+		inspect:   make(chan func(subtrees)),
+	}
+	branch.root = branch
+	if parent != nil {
+		branch.root = parent.root
+		branch.ctxValues = parent.ctxValues
+	}
+	if captureStacks.Load() {
+		branch.stacks = newBranchStacks()
 	}
 	if fn != nil {
 		branch.process = make(chan processFunc, 1)
@@ -69,18 +390,93 @@ func newTree(parent *tree, fn processFunc) *tree {
 	return branch
 }
 
-// Root return the single root (aka parent) of all sub-trees
+// Root return the single root (aka parent) of all sub-trees.
+//
+// It is started by startRoot while this package is initialized, which
+// only returns after a round-trip to its lifecycle, so the root is always
+// running before any package importing jungle can use it, even to prune it
+// right away.
 func Root() Tree {
 	return rootTree
 }
 
+// New creates a new root, independent from Root and any other tree.
+//
+// The lifecycle of the new tree is already running when New returns, so
+// it is safe to Prune it right away.
+func New(opts ...RootOption) Tree {
+	config := &rootConfig{}
+	for _, o := range opts {
+		o(config)
+	}
+	return startRoot(config)
+}
+
 func (t *tree) Branch() Tree {
 	return t.BranchFunc(nil)
 }
 
 func (t *tree) BranchFunc(fn func(Tree) error) Tree {
+	return t.grow(newTree(t, fn))
+}
+
+// BranchService creates a new branch that runs fn, but unlike BranchFunc
+// a nil error means the setup was completed and the branch stays alive
+// (usually serving its own children) until it is pruned.
+//
+// A non-nil error prunes the branch just like BranchFunc does.
+func (t *tree) BranchService(fn func(Tree) error) Tree {
 	branch := newTree(t, fn)
-	t.newBranch <- branch
+	branch.keepAlive = true
+	return t.grow(branch)
+}
+
+// grow sends the new branch to the lifecycle of t
+func (t *tree) grow(branch *tree) Tree {
+	if !t.admit(branch) {
+		return branch
+	}
+	if !t.reserve() {
+		branch.reject(ErrMaxBranches)
+		return branch
+	}
+	branch.counted = true
+	return t.attach(branch)
+}
+
+// admit rejects branch if t is paused or the admission control refuses it,
+// returning false in that case
+func (t *tree) admit(branch *tree) bool {
+	if t.lazy != nil {
+		t.Ensure()
+	}
+	if t.isPaused() {
+		branch.reject(ErrPaused)
+		return false
+	}
+	if !admitted() {
+		branch.reject(ErrNotAdmitted)
+		return false
+	}
+	return true
+}
+
+// attach sends the new branch to the lifecycle of t, without any of
+// the checks done by grow. If t was pruned, the branch is rejected.
+func (t *tree) attach(branch *tree) *tree {
+	// growMu guarantees that once the lifecycle is sealed no branch
+	// will be left in the buffer, never to be started
+	t.growMu.RLock()
+	defer t.growMu.RUnlock()
+	if t.sealed {
+		branch.reject(ErrParentPruned)
+		return branch
+	}
+	select {
+	case t.newBranch <- branch:
+	case <-t.prune:
+		branch.reject(ErrParentPruned)
+	}
 	// should we wait until fn is recieved to return ????
 	// is there a problem not waiting ????
 	// for now, this will be undefined behaviour
@@ -88,14 +484,43 @@ func (t *tree) BranchFunc(fn func(Tree) error) Tree {
 }
 
 func (t *tree) lifecycle() {
+	t.countGoroutines(1)
+	// done is reported to the same metrics which saw the start, so
+	// changing them in between doesn't unbalance their counts
+	lm, observed := currentLifecycleMetrics()
+	if observed {
+		lm.BranchStarted()
+	}
 	branches := &subtrees{}
 	defer func() {
+		// listeners must see the terminal events before anyone
+		// waiting on Done moves on
+		t.emit(Event{Kind: Pruned, PID: t.pid, Cause: t.cause})
+		t.flushListeners()
+		t.finishProgress()
+		t.release()
+		t.becameLeaf()
+		t.countGoroutines(-1)
+		if observed {
+			lm.BranchDone()
+		}
+		if !t.pruneStart.IsZero() {
+			t.lastPrune.Store(int64(t.config.now().Sub(t.pruneStart)))
+		}
 		close(t.done)
+		t.runDeferred()
 	}()
+	var expire *time.Timer
+	if at, ok := t.expiresAt(); ok {
+		expire = time.AfterFunc(time.Until(at), func() {
+			t.PruneWith(ErrDeadlineExceeded)
+		})
+	}
 	var pruned bool
-	selfErr := make(chan error, 1)
-	popChildren := make(chan *tree)
-	waitSelfProc := make(chan Signal)
+	var teardown <-chan time.Time
+	popChildren := make(chan *tree, t.config.controlBuffer)
+	// only created when there is a process, to save an allocation
+	var waitSelfProc chan Signal
 
 	// process function should not be a channel because
 	// if there is a process to wait we need to know
@@ -104,35 +529,84 @@ func (t *tree) lifecycle() {
 	//
 	// this is because the self-process is kind of a children process
 	// itself.
+	//
+	// the process is started before anything else, otherwise a prune
+	// signal that arrives first would leave us waiting for a process
+	// that never had the chance to run.
+	startProcess := func(fn processFunc) {
+		waitSelfProc = make(chan Signal)
+		procDone := waitSelfProc
+		run := func() {
+			currentMetrics().BranchStartLatency(time.Since(t.created))
+			untrack, unlabel := t.trackStack(), t.labelGoroutine()
+			err := t.filterErr(t.call(fn))
+			unlabel()
+			untrack()
+			t.err = err
+			if t.runner == nil {
+				t.countGoroutines(-1)
+			}
+			close(procDone)
+			if err == nil && t.keepAlive {
+				return
+			}
+			// Prune should never be called directly from lifecycle
+			// otherwise it will deadlock
+			t.PruneWith(err)
+		}
+		if t.runner != nil {
+			t.runner.submit(run)
+		} else {
+			t.countGoroutines(1)
+			go run()
+		}
+	}
+	if t.process != nil {
+		startProcess(<-t.process)
+	}
+	// lazy branches get their process later, see LazyBranch
+	var lazyProcess <-chan processFunc
+	if t.lazy != nil {
+		lazyProcess = t.lazy.process
+	}
 
 	for !pruned {
 		select {
+		case fn := <-lazyProcess:
+			lazyProcess = nil
+			startProcess(fn)
 		case c := <-popChildren:
 			branches.pop(c)
+			if len(*branches) == 0 {
+				t.becameLeaf()
+			}
 		case c := <-t.newBranch:
-			branches.append(c)
-			go func(c *tree) {
-				defer func() {
-					select {
-					case <-t.prune:
-					case popChildren <- c:
-					}
-				}()
-				c.lifecycle()
-			}(c)
-		case fn := <-t.process:
-			go func(fn processFunc) {
-				err := fn(t)
-				selfErr <- err
-				close(waitSelfProc)
-				// Prune should never be called directly from lifecycle
-				// otherwise it will deadlock
-				t.Prune()
-			}(fn)
-		case <-t.startPrune:
-			close(t.prune)
-			for _, c := range *branches {
-				c.Prune()
+			branches.start(c, popChildren)
+		case fn := <-t.inspect:
+			fn(*branches)
+		case <-t.prune:
+			if expire != nil {
+				expire.Stop()
+			}
+			if t.root == t {
+				t.pruneStart = t.config.now()
+			}
+			t.emit(Event{Kind: PruneStarted, PID: t.pid, Cause: t.cause})
+			if m, ok := currentLifecycleMetrics(); ok {
+				m.BranchPruned()
+			}
+			// the teardown timeout also bounds the prune itself, as
+			// dependencies and prune orders wait for children there
+			if t.config.teardownTimeout > 0 {
+				timer := time.NewTimer(t.config.teardownTimeout)
+				defer timer.Stop()
+				teardown = timer.C
+			}
+			if !branches.pruneAll(t.config, teardown) {
+				// the timer already fired, abandon right away
+				fired := make(chan time.Time, 1)
+				fired <- time.Now()
+				teardown = fired
 			}
 			pruned = true
 			break
@@ -140,15 +614,54 @@ func (t *tree) lifecycle() {
 	}
 
 	// wait for all children
-	for _, c := range *branches {
-		// should add a timeout of some sort here
-		// but lets not worry about it for now
-		<-c.Done()
+	var sealed bool
+	total, completed := len(*branches), 0
+	t.reportProgress(completed, total)
+wait:
+	for {
+		if len(*branches) == 0 && len(t.newBranch) == 0 {
+			if sealed {
+				break
+			}
+			// nothing left, but some branch might be on its way
+			// so stop accepting them and check again
+			t.seal()
+			sealed = true
+			continue
+		}
+		select {
+		case c := <-popChildren:
+			branches.pop(c)
+			if len(*branches) == 0 {
+				t.becameLeaf()
+			}
+			completed++
+			t.reportProgress(completed, total)
+		case c := <-t.newBranch:
+			// branches might still be waiting in the buffer, they are
+			// started just to be pruned right away
+			branches.start(c, popChildren)
+			c.Prune()
+			total++
+		case fn := <-t.inspect:
+			fn(*branches)
+		case <-teardown:
+			t.abandon(*branches)
+			break wait
+		}
 	}
 
-	if t.process != nil {
-		// wait until our own process is completed
-		<-waitSelfProc
+	if waitSelfProc != nil {
+		// wait until our own process is completed, still answering
+		// inspections so a stuck process can be debugged
+		for waiting := true; waiting; {
+			select {
+			case <-waitSelfProc:
+				waiting = false
+			case fn := <-t.inspect:
+				fn(*branches)
+			}
+		}
 	}
 	return
 }
@@ -162,6 +675,10 @@ func (t *tree) lifecycle() {
 // Currently this does not happen because there children have to run in
 // the same address space as their parent, but this is behavior is just
 // an implementation detail not a semantic guarantee.
+//
+// Done always returns the same channel and it is safe to call, receive
+// and select on it from any number of goroutines, before and after it is
+// closed. This is part of the contract and holds for Pruned as well.
 func (t *tree) Done() <-chan struct{} {
 	return t.done
 }
@@ -169,33 +686,294 @@ func (t *tree) Done() <-chan struct{} {
 // Prune is used to start the prune process on which this tree will notify
 // all of its children that they should stop (aka the children are Pruned).
 //
-// Prune returns as soon as the signal is captured by the tree,
-// use Done to wait until this tree and all of its
+// Prune returns as soon as the signal is sent, without waiting for the
+// lifecycle of the tree, use Done to wait until this tree and all of its
 // children have terminated.
 //
 // It is safe to call prune multiple times, only the first one will actually
 // have an impact in the system.
 func (t *tree) Prune() {
+	t.PruneWith(nil)
+}
+
+// PruneWith works just like Prune but records the reason why the tree was
+// pruned, which is later available from Cause and from the PruneStarted
+// event.
+//
+// Only the cause of the first prune is recorded.
+func (t *tree) PruneWith(cause error) {
+	t.pruneOnce.Do(func() {
+		t.cause = cause
+		t.pruned.Store(true)
+		close(t.prune)
+	})
+}
+
+// PruneCollect prunes this tree and waits until it is done, then it returns
+// the non-nil Err of this tree and of every descendant that was alive when
+// PruneCollect was called, which includes ErrDeadlineExceeded for branches
+// pruned by their deadline.
+//
+// Errors are wrapped in a *BranchError with the pid and name of the branch
+// which returned them. Branches that finished before the call are not
+// included, neither are the ones abandoned after the teardown timeout, see
+// WithTeardownTimeout, since they might never be done.
+func (t *tree) PruneCollect() []error {
+	var pruned []*tree
+	t.walk(func(c *tree) bool {
+		pruned = append(pruned, c)
+		return true
+	})
+	t.Prune()
+	<-t.Done()
+	var errs []error
+	for _, c := range pruned {
+		select {
+		case <-c.Done():
+		default:
+			// once t is done, only abandoned branches are still running
+			continue
+		}
+		if err := c.Err(); err != nil {
+			errs = append(errs, &BranchError{PID: c.pid, Name: c.Name(), Err: err})
+		}
+	}
+	return errs
+}
+
+// PruneChildren starts the prune process of all the direct children of
+// this tree, but unlike Prune the tree itself remains alive and can
+// be used to branch a fresh set of children.
+//
+// Branches created concurrently with PruneChildren might not be pruned.
+func (t *tree) PruneChildren() {
+	for _, c := range t.children() {
+		c.Prune()
+	}
+}
+
+// PruneChildrenWait is just like PruneChildren but waits until all the
+// pruned children are done.
+func (t *tree) PruneChildrenWait() {
+	children := t.children()
+	for _, c := range children {
+		c.Prune()
+	}
+	for _, c := range children {
+		<-c.Done()
+	}
+}
+
+// Cause returns the reason why this tree was pruned, which is either the
+// error given to PruneWith or the error returned by the process function.
+//
+// It is always nil before the tree is pruned.
+func (t *tree) Cause() error {
 	select {
 	case <-t.prune:
-		// prune already started as the channel is closed
+		return t.cause
+	default:
+		return nil
+	}
+}
+
+// Defer registers fn to be called after this tree and all of its children
+// are done, ie.: strictly after the channel from Done is closed.
+//
+// Deferred functions run in LIFO order, in a dedicated goroutine. If the
+// tree is already done, fn is called right away in its own goroutine.
+func (t *tree) Defer(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finalized {
+		go fn()
 		return
-	case t.startPrune <- Signal{}:
-		// prune didn't start, so lets wait until the tree
-		// receives the signal
+	}
+	t.deferred = append(t.deferred, fn)
+}
+
+// runDeferred calls the functions registered with Defer
+func (t *tree) runDeferred() {
+	t.mu.Lock()
+	fns := t.deferred
+	t.deferred = nil
+	t.finalized = true
+	t.mu.Unlock()
+	if len(fns) == 0 {
 		return
 	}
+	go func() {
+		for i := len(fns) - 1; i >= 0; i-- {
+			fns[i]()
+		}
+	}()
 }
 
-// Pruned indicates if this tree has received the signal to be pruned
+// Err returns the error returned by the process function of this tree,
+// it is always nil before the tree is done.
+//
+// If the tree was pruned by its own deadline or max lifetime and the
+// process function didn't return an error, ErrDeadlineExceeded is returned.
+func (t *tree) Err() error {
+	select {
+	case <-t.done:
+		if t.err == nil && t.TimedOut() {
+			return ErrDeadlineExceeded
+		}
+		return t.err
+	default:
+		return nil
+	}
+}
+
+// filterErr drops context.Canceled and context.DeadlineExceeded if the
+// tree was already pruned when the process function returned them and
+// the root was configured with WithIgnoreCancelErrors
+func (t *tree) filterErr(err error) error {
+	if err == nil || !t.config.ignoreCancelErrors || t.alive() {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
+}
+
+// IsPruned returns true once this tree received the signal to be pruned.
+//
+// It is a single atomic load, so process functions can check it in tight
+// loops, while Pruned is meant to be used in select statements. IsPruned
+// is always true before the channel from Pruned is closed.
+func (t *tree) IsPruned() bool {
+	return t.pruned.Load()
+}
+
+// Pruned indicates if this tree has received the signal to be pruned,
+// just like Done it is safe to use from many goroutines
 func (t *tree) Pruned() <-chan Signal {
 	return t.prune
 }
 
+// PID returns the unique identifier of this tree
+func (t *tree) PID() uint64 {
+	return t.pid
+}
+
+// Name returns the label of this tree, empty by default
+func (t *tree) Name() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.name
+}
+
+// SetName changes the label of this tree, names are not required to be
+// unique and can be used to find groups of related branches.
+func (t *tree) SetName(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.name = name
+}
+
+// SetTag attaches a key/value pair to this tree, like a region, shard or
+// version. Tags are included in snapshots and events.
+func (t *tree) SetTag(key, value string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tags == nil {
+		t.tags = make(map[string]string)
+	}
+	t.tags[key] = value
+}
+
+// Tags returns a copy of the tags of this tree, changing it
+// doesn't affect the tree
+func (t *tree) Tags() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.copyTags()
+}
+
+// copyTags returns a copy of the tags, or nil if there is none,
+// t.mu must be held
+func (t *tree) copyTags() map[string]string {
+	if len(t.tags) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(t.tags))
+	for k, v := range t.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// tag returns the value of a single tag
+func (t *tree) tag(key string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	v, ok := t.tags[key]
+	return v, ok
+}
+
+// IsRoot returns true if this tree doesn't have a parent
+func (t *tree) IsRoot() bool {
+	return t.parent == nil
+}
+
+// seal makes attach reject any new branch, it must be called only after
+// prune since it waits for all attach calls in progress
+func (t *tree) seal() {
+	t.growMu.Lock()
+	defer t.growMu.Unlock()
+	t.sealed = true
+}
+
+// rejectedTree returns a tree without parent which is already done with
+// cause, used when no real branch can be created
+func rejectedTree(cause error) *tree {
+	t := newTree(nil, nil)
+	t.reject(cause)
+	return t
+}
+
+// reject makes t done without ever starting its lifecycle,
+// t must not be visible to anyone else yet
+func (t *tree) reject(cause error) {
+	t.rejected = true
+	t.PruneWith(cause)
+	t.emit(Event{Kind: PruneStarted, PID: t.pid, Cause: cause})
+	t.emit(Event{Kind: Pruned, PID: t.pid, Cause: cause})
+	t.finishProgress()
+	t.release()
+	close(t.done)
+	t.runDeferred()
+}
+
 func (s *subtrees) append(c *tree) {
 	*s = append(*s, c)
 }
 
+// start keeps track of c and starts its lifecycle, once c is done
+// it is sent to popChildren
+func (s *subtrees) start(c *tree, popChildren chan<- *tree) {
+	s.append(c)
+	c.parent.touch()
+	go func(c *tree) {
+		// popChildren must always be delivered, even after prune,
+		// otherwise a child that finishes right as the parent starts
+		// pruning might never leave the bookkeeping. The parent keeps
+		// receiving from popChildren until all of its branches are gone,
+		// or it abandoned them after its teardown timeout and is done.
+		defer func() {
+			c.parent.touch()
+			select {
+			case popChildren <- c:
+			case <-c.parent.done:
+			}
+		}()
+		c.lifecycle()
+	}(c)
+}
+
 func (s *subtrees) pop(c *tree) {
 	changed := -1
 	for i, v := range *s {
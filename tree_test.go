@@ -1,7 +1,9 @@
 package jungle
 
 import (
+	"errors"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -32,3 +34,297 @@ func TestLifecycle(t *testing.T) {
 		t.Fatalf("count should be 2 but got %v", atomic.LoadInt32(&count))
 	}
 }
+
+func TestPruneWhileChildrenComplete(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		localRoot := Root().Branch()
+		var count int32
+		for j := 0; j < 100; j++ {
+			localRoot.BranchFunc(func(branch Tree) error {
+				atomic.AddInt32(&count, 1)
+				return nil
+			})
+		}
+		// some children might be completing at the same time
+		// the parent is pruned
+		localRoot.Prune()
+		select {
+		case <-localRoot.Done():
+		case <-time.After(time.Second * 5):
+			t.Fatalf("tree should be done by now, %v children completed", atomic.LoadInt32(&count))
+		}
+		if atomic.LoadInt32(&count) != 100 {
+			t.Fatalf("count should be 100 but got %v", atomic.LoadInt32(&count))
+		}
+	}
+}
+
+func TestIsRoot(t *testing.T) {
+	if !Root().IsRoot() {
+		t.Fatal("Root should be the root")
+	}
+	branch := Root().Branch()
+	defer branch.Prune()
+	if branch.IsRoot() {
+		t.Fatal("a branch should never be the root")
+	}
+}
+
+func TestPruneChildren(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	var count int32
+	for i := 0; i < 3; i++ {
+		localRoot.BranchFunc(func(branch Tree) error {
+			<-branch.Pruned()
+			atomic.AddInt32(&count, 1)
+			return nil
+		})
+	}
+	localRoot.PruneChildrenWait()
+	if atomic.LoadInt32(&count) != 3 {
+		t.Fatalf("count should be 3 but got %v", atomic.LoadInt32(&count))
+	}
+	select {
+	case <-localRoot.Pruned():
+		t.Fatal("parent should not be pruned")
+	default:
+	}
+
+	done := make(chan Signal)
+	localRoot.BranchFunc(func(branch Tree) error {
+		close(done)
+		return nil
+	})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("parent should accept new branches")
+	}
+}
+
+func TestBranchService(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	service := localRoot.BranchService(func(branch Tree) error {
+		branch.Branch()
+		return nil
+	})
+	select {
+	case <-service.Pruned():
+		t.Fatal("service should stay alive after a nil error")
+	case <-time.After(time.Millisecond * 100):
+	}
+	service.Prune()
+	<-service.Done()
+
+	failed := localRoot.BranchService(func(branch Tree) error {
+		return errors.New("setup failed")
+	})
+	select {
+	case <-failed.Done():
+	case <-time.After(time.Second):
+		t.Fatal("service should be pruned after an error")
+	}
+}
+
+func TestNew(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		root := New()
+		if !root.IsRoot() {
+			t.Fatal("New should return a root")
+		}
+		// prune right away
+		root.Prune()
+		select {
+		case <-root.Done():
+		case <-time.After(time.Second):
+			t.Fatal("root should be done after prune")
+		}
+	}
+
+	root := New()
+	child := root.Branch()
+	child.Prune()
+	root.Prune()
+	<-root.Done()
+	<-child.Done()
+}
+
+func TestPruneCollect(t *testing.T) {
+	root := New()
+	first := errors.New("first")
+	second := errors.New("second")
+	for _, err := range []error{first, second, nil} {
+		err := err
+		root.Branch().BranchFunc(func(b Tree) error {
+			<-b.Pruned()
+			return err
+		})
+	}
+	errs := root.PruneCollect()
+	if len(errs) != 2 {
+		t.Fatalf("expecting 2 errors got %v", errs)
+	}
+	var foundFirst, foundSecond bool
+	for _, err := range errs {
+		foundFirst = foundFirst || errors.Is(err, first)
+		foundSecond = foundSecond || errors.Is(err, second)
+	}
+	if !foundFirst || !foundSecond {
+		t.Fatalf("all errors should be collected, got %v", errs)
+	}
+}
+
+func TestPruneCollectDeadline(t *testing.T) {
+	root := New()
+	expired := root.BranchDeadline(time.Now().Add(time.Millisecond))
+	expired.BranchFunc(func(Tree) error {
+		// keeps the expired branch alive until PruneCollect prunes the root
+		<-root.Pruned()
+		return nil
+	})
+	<-expired.Pruned()
+	errs := root.PruneCollect()
+	if len(errs) != 1 || !errors.Is(errs[0], ErrDeadlineExceeded) {
+		t.Fatalf("expecting the deadline of the branch got %v", errs)
+	}
+}
+
+func TestIsPruned(t *testing.T) {
+	branch := Root().Branch()
+	if branch.IsPruned() {
+		t.Fatal("branch should not be pruned")
+	}
+	branch.Prune()
+	<-branch.Pruned()
+	if !branch.IsPruned() {
+		t.Fatal("branch should be pruned")
+	}
+}
+
+func BenchmarkPrunedSelect(b *testing.B) {
+	branch := Root().Branch()
+	defer branch.Prune()
+	for i := 0; i < b.N; i++ {
+		select {
+		case <-branch.Pruned():
+			b.Fatal("should not be pruned")
+		default:
+		}
+	}
+}
+
+func BenchmarkIsPruned(b *testing.B) {
+	branch := Root().Branch()
+	defer branch.Prune()
+	for i := 0; i < b.N; i++ {
+		if branch.IsPruned() {
+			b.Fatal("should not be pruned")
+		}
+	}
+}
+
+func TestDefer(t *testing.T) {
+	branch := Root().Branch()
+	var order []int
+	finished := make(chan Signal)
+	branch.Defer(func() {
+		order = append(order, 1)
+		close(finished)
+	})
+	branch.Defer(func() {
+		select {
+		case <-branch.Done():
+		default:
+			t.Error("deferred functions should run after Done")
+		}
+		order = append(order, 2)
+	})
+	branch.Prune()
+	<-finished
+	if len(order) != 2 || order[0] != 2 || order[1] != 1 {
+		t.Fatalf("deferred functions should run in LIFO order, got %v", order)
+	}
+
+	late := make(chan Signal)
+	branch.Defer(func() { close(late) })
+	select {
+	case <-late:
+	case <-time.After(time.Second):
+		t.Fatal("Defer after Done should run right away")
+	}
+}
+
+func TestConcurrentDoneAndPruned(t *testing.T) {
+	branch := Root().Branch()
+	const readers = 1000
+	var wg sync.WaitGroup
+	wg.Add(readers)
+	for i := 0; i < readers; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				<-branch.Pruned()
+			}
+			select {
+			case <-branch.Done():
+			case <-time.After(time.Second):
+				t.Error("Done should be closed")
+			}
+			// reading again after close must not block
+			<-branch.Done()
+			<-branch.Pruned()
+		}()
+	}
+	branch.Prune()
+	wg.Wait()
+	if branch.Done() != branch.Done() || branch.Pruned() != branch.Pruned() {
+		t.Fatal("Done and Pruned should always return the same channel")
+	}
+}
+
+func TestConcurrentPruneWith(t *testing.T) {
+	branch := Root().Branch()
+	causes := make([]error, 50)
+	var wg sync.WaitGroup
+	for i := range causes {
+		causes[i] = errors.New("cause")
+		wg.Add(1)
+		go func(err error) {
+			defer wg.Done()
+			branch.PruneWith(err)
+			if !branch.IsPruned() {
+				t.Error("tree should be pruned once PruneWith returns")
+			}
+		}(causes[i])
+	}
+	wg.Wait()
+	<-branch.Done()
+	var matches int
+	for _, c := range causes {
+		if branch.Cause() == c {
+			matches++
+		}
+	}
+	if matches != 1 {
+		t.Fatalf("exactly one cause should be recorded, got %v", matches)
+	}
+	events := branch.Events()
+	if e := <-events; e.Kind != PruneStarted || e.Cause != branch.Cause() {
+		t.Fatalf("PruneStarted should carry the recorded cause, got %v", e)
+	}
+}
+
+func TestStartRootPrunedRightAway(t *testing.T) {
+	// same path as the default root, pruned right away
+	root := startRoot(defaultConfig)
+	root.Prune()
+	select {
+	case <-root.Done():
+	case <-time.After(time.Second):
+		t.Fatal("a root pruned right after starting should be done")
+	}
+}
@@ -0,0 +1,61 @@
+package jungle
+
+type (
+	// ValueKey identifies a value stored in a tree, each key created by
+	// NewValueKey is unique, even if two keys share the same name, so
+	// values from different packages never collide.
+	ValueKey[T any] struct {
+		name string
+	}
+)
+
+// NewValueKey returns a new key for values of type T, name is only used
+// for debugging.
+func NewValueKey[T any](name string) *ValueKey[T] {
+	return &ValueKey[T]{name: name}
+}
+
+// String returns the name of the key
+func (k *ValueKey[T]) String() string {
+	return k.name
+}
+
+// Set stores v in t, it is visible to t and all of its descendants,
+// unless one of them sets its own value.
+func (k *ValueKey[T]) Set(t Tree, v T) {
+	if tt, ok := t.(*tree); ok {
+		tt.setValue(k, v)
+	}
+}
+
+// Get returns the value from t or from its closest ancestor which has one
+func (k *ValueKey[T]) Get(t Tree) (T, bool) {
+	if tt, ok := t.(*tree); ok {
+		if v, found := tt.value(k); found {
+			return v.(T), true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (t *tree) setValue(key, v interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.values == nil {
+		t.values = make(map[interface{}]interface{})
+	}
+	t.values[key] = v
+}
+
+func (t *tree) value(key interface{}) (interface{}, bool) {
+	for p := t; p != nil; p = p.parent {
+		p.mu.Lock()
+		v, found := p.values[key]
+		p.mu.Unlock()
+		if found {
+			return v, true
+		}
+	}
+	return nil, false
+}
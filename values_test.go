@@ -0,0 +1,39 @@
+package jungle
+
+import "testing"
+
+func TestValueKey(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+
+	region := NewValueKey[string]("region")
+	other := NewValueKey[string]("region")
+	retries := NewValueKey[int]("retries")
+
+	child := localRoot.Branch()
+	grandchild := child.Branch()
+
+	region.Set(localRoot, "us-east")
+	retries.Set(child, 3)
+
+	if v, ok := region.Get(grandchild); !ok || v != "us-east" {
+		t.Fatalf("value should be inherited, got %v %v", v, ok)
+	}
+	if _, ok := other.Get(grandchild); ok {
+		t.Fatal("keys with the same name should not collide")
+	}
+	if v, ok := retries.Get(grandchild); !ok || v != 3 {
+		t.Fatalf("expecting 3 got %v %v", v, ok)
+	}
+	if _, ok := retries.Get(localRoot); ok {
+		t.Fatal("values should not be visible to ancestors")
+	}
+
+	region.Set(child, "eu-west")
+	if v, _ := region.Get(grandchild); v != "eu-west" {
+		t.Fatalf("closest value should win, got %v", v)
+	}
+	if v, _ := region.Get(localRoot); v != "us-east" {
+		t.Fatalf("ancestor value should not change, got %v", v)
+	}
+}
@@ -0,0 +1,58 @@
+package jungle
+
+import (
+	"context"
+	"reflect"
+)
+
+// Wait receives a value from ch, unless t is pruned first, in which case
+// it returns the zero value and false. It also returns false if ch is
+// closed.
+//
+// Process functions should use Wait instead of a plain receive, so a
+// blocked receive never keeps the tree from being pruned:
+//
+//	for {
+//		job, ok := jungle.Wait(t, jobs)
+//		if !ok {
+//			return nil
+//		}
+//		...
+//	}
+func Wait[T any](t Tree, ch <-chan T) (T, bool) {
+	select {
+	case v, ok := <-ch:
+		return v, ok
+	case <-t.Pruned():
+		var zero T
+		return zero, false
+	}
+}
+
+// WaitDoneContext blocks until t is done or ctx is done, in which case
+// ctx.Err() is returned. t is not pruned when ctx is done.
+func WaitDoneContext(ctx context.Context, t Tree) error {
+	select {
+	case <-t.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// First blocks until one of trees is done and returns it along with its
+// index. If more than one is already done, any of them might be returned.
+//
+// No goroutine is left waiting on the other trees. Without trees First
+// returns nil and -1 right away.
+func First(trees ...Tree) (Tree, int) {
+	if len(trees) == 0 {
+		return nil, -1
+	}
+	cases := make([]reflect.SelectCase, len(trees))
+	for i, t := range trees {
+		cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(t.Done())}
+	}
+	i, _, _ := reflect.Select(cases)
+	return trees[i], i
+}
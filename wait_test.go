@@ -0,0 +1,83 @@
+package jungle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 42
+	branch := Root().Branch()
+	defer branch.Prune()
+	if v, ok := Wait(branch, ch); !ok || v != 42 {
+		t.Fatalf("expecting 42 got %v %v", v, ok)
+	}
+	close(ch)
+	if _, ok := Wait(branch, ch); ok {
+		t.Fatal("a closed channel should return false")
+	}
+}
+
+func TestWaitInterruptedByPrune(t *testing.T) {
+	blocked := make(chan int)
+	result := make(chan bool, 1)
+	branch := Root().BranchFunc(func(t Tree) error {
+		_, ok := Wait(t, blocked)
+		result <- ok
+		return nil
+	})
+	branch.Prune()
+	select {
+	case <-branch.Done():
+	case <-time.After(time.Second):
+		t.Fatal("prune should interrupt Wait")
+	}
+	if <-result {
+		t.Fatal("Wait should return false when pruned")
+	}
+}
+
+func TestFirst(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+	var trees []Tree
+	for _, d := range []time.Duration{50, 10, 30} {
+		d := d * time.Millisecond
+		trees = append(trees, parent.BranchFunc(func(Tree) error {
+			time.Sleep(d)
+			return nil
+		}))
+	}
+	first, i := First(trees...)
+	if i != 1 || first != trees[1] {
+		t.Fatalf("expecting the second tree to finish first got %v", i)
+	}
+	if _, i := First(trees[0], trees[2]); i != 1 {
+		t.Fatalf("expecting the third tree to finish before the first got %v", i)
+	}
+	if first, i := First(); first != nil || i != -1 {
+		t.Fatalf("First without trees should return nil and -1 got %v %v", first, i)
+	}
+}
+
+func TestWaitDoneContext(t *testing.T) {
+	parent := Root().Branch()
+	defer parent.Prune()
+
+	done := parent.BranchFunc(func(Tree) error { return nil })
+	if err := WaitDoneContext(context.Background(), done); err != nil {
+		t.Fatalf("expecting nil got %v", err)
+	}
+
+	alive := parent.Branch()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := WaitDoneContext(ctx, alive); err != context.Canceled {
+		t.Fatalf("expecting context.Canceled got %v", err)
+	}
+	if alive.IsPruned() {
+		t.Fatal("the tree should not be pruned when ctx is done")
+	}
+}
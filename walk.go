@@ -0,0 +1,109 @@
+package jungle
+
+// Walk visits this tree and then all of its live descendants, depth-first,
+// stopping as soon as fn returns false.
+//
+// The children of each tree are obtained from its own lifecycle, so Walk
+// is safe to call while branches are created or pruned concurrently. Trees
+// that were pruned are still visited but their children are not, since
+// they are being pruned as well.
+func (t *tree) Walk(fn func(Tree) bool) {
+	t.walk(func(t *tree) bool { return fn(t) })
+}
+
+// FindByPID searches this tree and its live descendants for the branch
+// with the given pid.
+func (t *tree) FindByPID(pid uint64) (Tree, bool) {
+	var found Tree
+	t.walk(func(t *tree) bool {
+		if t.pid == pid && t.alive() {
+			found = t
+		}
+		return found == nil
+	})
+	return found, found != nil
+}
+
+// FindByName returns this tree and all of its live descendants with the
+// given name.
+func (t *tree) FindByName(name string) []Tree {
+	return t.Find(func(c Tree) bool { return c.Name() == name })
+}
+
+// FindByTag returns this tree and all of its live descendants where the
+// tag key is set to value.
+func (t *tree) FindByTag(key, value string) []Tree {
+	return t.Find(func(c Tree) bool {
+		v, ok := c.(*tree).tag(key)
+		return ok && v == value
+	})
+}
+
+// Find returns this tree and all of its live descendants for which
+// pred returns true.
+func (t *tree) Find(pred func(Tree) bool) []Tree {
+	var found []Tree
+	t.walk(func(c *tree) bool {
+		if c.alive() && pred(c) {
+			found = append(found, c)
+		}
+		return true
+	})
+	return found
+}
+
+// PruneWhere prunes this tree and all of its live descendants for which
+// pred returns true, it returns how many trees were pruned.
+//
+// It doesn't wait for the pruned trees to be done.
+func (t *tree) PruneWhere(pred func(Tree) bool) int {
+	found := t.Find(pred)
+	for _, c := range found {
+		c.Prune()
+	}
+	return len(found)
+}
+
+func (t *tree) walk(fn func(*tree) bool) bool {
+	if !fn(t) {
+		return false
+	}
+	for _, c := range t.children() {
+		if !c.walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// children returns a copy of the live branches of t, asking the lifecycle
+// for them. Once t is pruned it returns nil.
+func (t *tree) children() []*tree {
+	if !t.alive() {
+		return nil
+	}
+	reply := make(chan []*tree, 1)
+	select {
+	case t.inspect <- func(s subtrees) { reply <- append([]*tree(nil), s...) }:
+		return <-reply
+	case <-t.prune:
+		return nil
+	}
+}
+
+// remaining returns a copy of the branches of t which are not done yet,
+// unlike children it keeps working while t is pruning
+func (t *tree) remaining() []*tree {
+	reply := make(chan []*tree, 1)
+	select {
+	case t.inspect <- func(s subtrees) { reply <- append([]*tree(nil), s...) }:
+		return <-reply
+	case <-t.done:
+		return nil
+	}
+}
+
+// alive returns true while t wasn't pruned
+func (t *tree) alive() bool {
+	return !t.pruned.Load()
+}
@@ -0,0 +1,156 @@
+package jungle
+
+import "testing"
+
+func TestFindByPID(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	child := localRoot.Branch().Branch().Branch()
+
+	found, ok := localRoot.FindByPID(child.PID())
+	if !ok {
+		t.Fatalf("pid %v should be found", child.PID())
+	}
+	if found != child {
+		t.Fatalf("expecting %v got %v", child.PID(), found.PID())
+	}
+
+	if _, ok := localRoot.FindByPID(0); ok {
+		t.Fatal("pid 0 should never be found")
+	}
+
+	child.Prune()
+	<-child.Done()
+	if _, ok := localRoot.FindByPID(child.PID()); ok {
+		t.Fatal("pruned branches should not be found")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	a := localRoot.Branch()
+	a.Branch()
+	localRoot.Branch()
+
+	var count int
+	localRoot.Walk(func(Tree) bool {
+		count++
+		return true
+	})
+	if count != 4 {
+		t.Fatalf("should visit 4 trees but got %v", count)
+	}
+
+	count = 0
+	localRoot.Walk(func(Tree) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Fatalf("walk should stop after 2 trees but got %v", count)
+	}
+}
+
+func TestFindByName(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	for i := 0; i < 3; i++ {
+		w := localRoot.Branch()
+		w.SetName("worker")
+		w.Branch().SetName("helper")
+	}
+	localRoot.Branch().SetName("other")
+
+	if found := localRoot.FindByName("worker"); len(found) != 3 {
+		t.Fatalf("should find 3 workers got %v", len(found))
+	}
+	if found := localRoot.FindByName("helper"); len(found) != 3 {
+		t.Fatalf("should find 3 helpers got %v", len(found))
+	}
+	if found := localRoot.FindByName("missing"); len(found) != 0 {
+		t.Fatalf("should find nothing got %v", len(found))
+	}
+}
+
+func TestTags(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	for i := 0; i < 4; i++ {
+		b := localRoot.Branch()
+		b.SetTag("region", "us")
+		if i%2 == 0 {
+			b.SetTag("version", "old")
+		} else {
+			b.SetTag("version", "new")
+		}
+	}
+
+	if found := localRoot.FindByTag("version", "old"); len(found) != 2 {
+		t.Fatalf("expecting 2 old branches got %v", len(found))
+	}
+	if found := localRoot.FindByTag("region", "us"); len(found) != 4 {
+		t.Fatalf("expecting 4 branches got %v", len(found))
+	}
+
+	b := localRoot.Branch()
+	b.SetTag("shard", "1")
+	tags := b.Tags()
+	tags["shard"] = "2"
+	if v := b.Tags()["shard"]; v != "1" {
+		t.Fatalf("tags should be copied on read, got %v", v)
+	}
+	if snap := Snapshot(b); snap.Tags["shard"] != "1" {
+		t.Fatalf("snapshot should include tags, got %v", snap.Tags)
+	}
+	events := b.Events()
+	b.Prune()
+	if e := <-events; e.Tags["shard"] != "1" {
+		t.Fatalf("events should include tags, got %v", e.Tags)
+	}
+}
+
+func TestFindAndPruneWhere(t *testing.T) {
+	localRoot := Root().Branch()
+	defer localRoot.Prune()
+	var old []Tree
+	for i := 0; i < 6; i++ {
+		b := localRoot.Branch()
+		if i < 4 {
+			b.SetTag("version", "old")
+			old = append(old, b)
+		}
+	}
+	isOld := func(c Tree) bool { return c.Tags()["version"] == "old" }
+
+	if found := localRoot.Find(isOld); len(found) != 4 {
+		t.Fatalf("expecting 4 old branches got %v", len(found))
+	}
+	if n := localRoot.PruneWhere(isOld); n != 4 {
+		t.Fatalf("expecting 4 pruned branches got %v", n)
+	}
+	for _, b := range old {
+		<-b.Done()
+	}
+	if found := localRoot.Find(isOld); len(found) != 0 {
+		t.Fatalf("old branches should be gone, got %v", len(found))
+	}
+	if found := localRoot.Find(func(Tree) bool { return true }); len(found) != 3 {
+		t.Fatalf("root and 2 new branches should be alive, got %v", len(found))
+	}
+}
+
+func TestRootOf(t *testing.T) {
+	root := New()
+	defer root.Prune()
+	deep := root
+	for i := 0; i < 5; i++ {
+		deep = deep.Branch()
+	}
+	if RootOf(deep) != root || RootOf(root) != root {
+		t.Fatal("RootOf should return the root created by New")
+	}
+	if RootOf(Root().Branch().Branch()) != Root() {
+		t.Fatal("RootOf should return the default root")
+	}
+}